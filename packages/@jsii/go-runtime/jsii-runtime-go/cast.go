@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/aws/jsii-runtime-go/internal/api"
 	"github.com/aws/jsii-runtime-go/internal/kernel"
 )
 
@@ -11,49 +12,110 @@ import (
 // pointer. Panics if the from value is not a jsii proxy object, or if the to
 // value is not a pointer to an interface type.
 func UnsafeCast(from interface{}, into interface{}) {
-	rinto := reflect.ValueOf(into)
+	rfrom, rinto, done := prepareCast("UnsafeCast", from, into)
+	if done {
+		return
+	}
+
+	client := kernel.GetClient()
+	if objID, found := client.FindObjectRef(rfrom); found {
+		// Ensures the value is initialized properly. Panics if the target value is not a jsii interface type.
+		client.Types().InitJsiiProxy(rinto, rinto.Type())
+
+		// Make the new value an alias to the old value.
+		client.RegisterInstance(rinto, api.ObjectRef{InstanceID: objID.InstanceID})
+
+		// If the target type is a behavioral interface, add it to the object's interfaces list.
+		if fqn, found := client.Types().InterfaceFQN(rinto.Type()); found {
+			client.AssumeInterface(objID, fqn)
+		}
+		return
+	}
+
+	panic(fmt.Errorf("first argument to UnsafeCast must be a jsii proxy value; received %v", rfrom))
+}
+
+// Cast converts the given interface value to the desired target interface
+// pointer, after verifying that the object actually implements the target
+// interface. Unlike UnsafeCast, the verification uses the object's dynamic
+// jsii type and the interfaces it was declared to implement, as reported by
+// the jsii kernel, and their supertypes, as declared by the loaded libraries,
+// rather than trusting the caller.
+//
+// Returns an error (and leaves the target untouched) if the object is not
+// known to implement the target interface. Panics if the to value is not a
+// pointer to an interface type.
+func Cast(from interface{}, into interface{}) error {
+	rfrom, rinto, done := prepareCast("Cast", from, into)
+	if done {
+		return nil
+	}
+
+	client := kernel.GetClient()
+	objID, found := client.FindObjectRef(rfrom)
+	if !found {
+		return fmt.Errorf("%v does not implement %v, and is not a jsii proxy value", rfrom.Type(), rinto.Type())
+	}
+	if err := client.VerifyImplements(objID, rinto.Type()); err != nil {
+		return err
+	}
+
+	// Initialize a new proxy value, so we don't leave into in a partial state
+	// should this fail.
+	proxy := reflect.New(rinto.Type()).Elem()
+	if err := client.Types().InitJsiiProxy(proxy, proxy.Type()); err != nil {
+		return err
+	}
+	// Make the new value an alias to the old value.
+	if err := client.RegisterInstance(proxy, api.ObjectRef{InstanceID: objID.InstanceID}); err != nil {
+		return err
+	}
+	rinto.Set(proxy)
+	return nil
+}
+
+// TryCast attempts to convert the given interface value to the desired target
+// interface pointer, like Cast does. It returns true if the conversion was
+// successful, and false (leaving the target untouched) if the object does not
+// implement the target interface. Panics if the to value is not a pointer to
+// an interface type.
+func TryCast(from interface{}, into interface{}) bool {
+	return Cast(from, into) == nil
+}
+
+// prepareCast validates the arguments of a cast function named fn, and
+// handles the trivial cases (nil source, or source directly assignable to the
+// target). Returns the reflect.Value of the source, the target interface
+// value, and whether the cast was already completed.
+func prepareCast(fn string, from interface{}, into interface{}) (rfrom reflect.Value, rinto reflect.Value, done bool) {
+	rinto = reflect.ValueOf(into)
 	if rinto.Kind() != reflect.Ptr {
-		panic(fmt.Errorf("second argument to UnsafeCast must be a pointer to an interface; received %v", rinto.Type()))
+		panic(fmt.Errorf("second argument to %v must be a pointer to an interface; received %v", fn, rinto.Type()))
 	}
 	rinto = rinto.Elem()
 	if rinto.Kind() != reflect.Interface {
-		panic(fmt.Errorf("second argument to UnsafeCast must be a pointer to an interface; received pointer to %v", rinto.Type()))
+		panic(fmt.Errorf("second argument to %v must be a pointer to an interface; received pointer to %v", fn, rinto.Type()))
 	}
 
-	rfrom := reflect.ValueOf(from)
+	rfrom = reflect.ValueOf(from)
 
 	// If rfrom is essentially nil, set into to nil and return.
 	if !rfrom.IsValid() || rfrom.IsZero() {
 		null := reflect.Zero(rinto.Type())
 		rinto.Set(null)
+		done = true
 		return
 	}
 	// Interfaces may present as a pointer to an implementing struct, and that's fine...
 	if rfrom.Kind() != reflect.Interface && rfrom.Kind() != reflect.Ptr {
-		panic(fmt.Errorf("first argument to UnsafeCast must be an interface value; received %v", rfrom.Type()))
+		panic(fmt.Errorf("first argument to %v must be an interface value; received %v", fn, rfrom.Type()))
 	}
 
 	// If rfrom can be directly converted to rinto, just do it.
 	if rfrom.Type().AssignableTo(rinto.Type()) {
 		rfrom = rfrom.Convert(rinto.Type())
 		rinto.Set(rfrom)
-		return
-	}
-
-	client := kernel.GetClient()
-	if objID, found := client.FindObjectRef(rfrom); found {
-		// Ensures the value is initialized properly. Panics if the target value is not a jsii interface type.
-		client.Types().InitJsiiProxy(rinto, rinto.Type())
-
-		// If the target type is a behavioral interface, add it to the ObjectRef.Interfaces list.
-		if fqn, found := client.Types().InterfaceFQN(rinto.Type()); found {
-			objID.Interfaces = append(objID.Interfaces, fqn)
-		}
-
-		// Make the new value an alias to the old value.
-		client.RegisterInstance(rinto, objID)
-		return
+		done = true
 	}
-
-	panic(fmt.Errorf("first argument to UnsafeCast must be a jsii proxy value; received %v", rfrom))
+	return
 }
@@ -99,3 +99,103 @@ func TestRegistersAlias(t *testing.T) {
 		t.Fail()
 	}
 }
+
+type MockInterfaceC interface {
+	MockMethodC()
+}
+
+type mockC struct {
+	_ int // Padding
+}
+
+func (m *mockC) MockMethodC() {}
+
+type MockClassA interface {
+	MockInterfaceA
+}
+
+type MockClassAC interface {
+	MockInterfaceA
+	MockInterfaceC
+}
+
+func registerCastMocks(t *testing.T) *kernel.Client {
+	client := kernel.GetClient()
+	registry := client.Types()
+
+	if err := registry.RegisterInterface(api.FQN("mock.InterfaceC"), reflect.TypeOf((*MockInterfaceC)(nil)).Elem(), []api.Override{}, func() interface{} { return &mockC{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterClass(api.FQN("mock.ClassA"), reflect.TypeOf((*MockClassA)(nil)).Elem(), []api.Override{}, func() interface{} { return NewMockInterfaceA() }); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterClass(api.FQN("mock.ClassAC"), reflect.TypeOf((*MockClassAC)(nil)).Elem(), []api.Override{}, func() interface{} { return NewMockInterfaceA() }); err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestCastUsesInstanceType(t *testing.T) {
+	client := registerCastMocks(t)
+
+	from := NewMockInterfaceA()
+	client.RegisterInstance(reflect.ValueOf(from), api.ObjectRef{InstanceID: "mock.ClassAC@10001"})
+
+	var into MockInterfaceC
+	if err := Cast(from, &into); err != nil {
+		t.Fatal(err)
+	}
+	if refid, found := client.FindObjectRef(reflect.ValueOf(into)); !found || refid.InstanceID != "mock.ClassAC@10001" {
+		t.Errorf("expected cast result to alias mock.ClassAC@10001, got %v", refid)
+	}
+}
+
+func TestCastUsesDeclaredInterfaces(t *testing.T) {
+	client := registerCastMocks(t)
+
+	from := NewMockInterfaceA()
+	client.RegisterInstance(reflect.ValueOf(from), api.ObjectRef{InstanceID: "Object@10002", Interfaces: []api.FQN{"mock.InterfaceC"}})
+
+	var into MockInterfaceC
+	if !TryCast(from, &into) {
+		t.Error("expected cast to succeed")
+	}
+}
+
+func TestCastRejectsUnimplementedInterface(t *testing.T) {
+	client := registerCastMocks(t)
+
+	from := NewMockInterfaceA()
+	client.RegisterInstance(reflect.ValueOf(from), api.ObjectRef{InstanceID: "mock.ClassA@10003"})
+
+	var into MockInterfaceC
+	if err := Cast(from, &into); err == nil {
+		t.Error("expected an error, but cast succeeded")
+	}
+	if into != nil {
+		t.Errorf("expected target to be left untouched, got %v", into)
+	}
+
+	// Unsafe casting does not make the interface verifiably implemented.
+	var unsafe MockInterfaceC
+	UnsafeCast(from, &unsafe)
+	if unsafe == nil {
+		t.Error("expected UnsafeCast to succeed")
+	}
+	if TryCast(from, &into) {
+		t.Error("expected cast to fail after UnsafeCast")
+	}
+
+	// The assumed interface is still reported to the kernel.
+	if refid, found := client.FindObjectRef(reflect.ValueOf(from)); !found || len(refid.Interfaces) != 1 || refid.Interfaces[0] != "mock.InterfaceC" {
+		t.Errorf("expected mock.InterfaceC in the interfaces list, got %v", refid.Interfaces)
+	}
+}
+
+func TestCastRejectsNonProxyValue(t *testing.T) {
+	var into MockInterfaceC
+	if err := Cast(NewMockInterfaceB(), &into); err == nil {
+		t.Error("expected an error, but cast succeeded")
+	}
+}
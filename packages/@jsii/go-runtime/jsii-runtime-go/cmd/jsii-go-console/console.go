@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+// kernelClient is the subset of the kernel.Client API used by the console.
+type kernelClient interface {
+	Load(props kernel.LoadProps, tarball []byte) (kernel.LoadResponse, error)
+	Create(props kernel.CreateProps) (kernel.CreateResponse, error)
+	Del(props kernel.DelProps) (kernel.DelResponse, error)
+	Invoke(props kernel.InvokeProps) (kernel.InvokeResponse, error)
+	SInvoke(props kernel.StaticInvokeProps) (kernel.InvokeResponse, error)
+	Get(props kernel.GetProps) (kernel.GetResponse, error)
+	SGet(props kernel.StaticGetProps) (kernel.GetResponse, error)
+	Set(props kernel.SetProps) (kernel.SetResponse, error)
+	SSet(props kernel.StaticSetProps) (kernel.SetResponse, error)
+	Stats() (kernel.StatsResponse, error)
+	SetTrace(out io.Writer)
+}
+
+// console executes commands against the kernel, and keeps track of the objects
+// obtained from it, which commands designate as $1, $2, ...
+type console struct {
+	client kernelClient
+	out    io.Writer
+
+	// objects are the references obtained so far, $1 being objects[0]. Deleted
+	// objects are left as empty references, so numbering remains stable.
+	objects []protocol.ObjectRef
+	// labels associates instance IDs with their index in objects.
+	labels map[string]int
+
+	// done is set once the quit command was executed.
+	done bool
+	// failed is set once any command failed.
+	failed bool
+}
+
+func newConsole(client kernelClient, out io.Writer) *console {
+	return &console{client: client, out: out, labels: make(map[string]int)}
+}
+
+type command struct {
+	usage       string
+	description string
+	minArgs     int
+	maxArgs     int // -1 for no limit
+	run         func(c *console, args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"load":    {"load <tarball>", "loads an assembly from its npm package tarball", 1, 1, (*console).load},
+		"create":  {"create <fqn> [arg ...]", "creates an instance of a class", 1, -1, (*console).create},
+		"invoke":  {"invoke <object> <method> [arg ...]", "calls a method of an object", 2, -1, (*console).invoke},
+		"sinvoke": {"sinvoke <fqn> <method> [arg ...]", "calls a static method of a class", 2, -1, (*console).sinvoke},
+		"get":     {"get <object> <property>", "reads a property of an object", 2, 2, (*console).get},
+		"sget":    {"sget <fqn> <property>", "reads a static property of a class", 2, 2, (*console).sget},
+		"set":     {"set <object> <property> <value>", "writes a property of an object", 3, 3, (*console).set},
+		"sset":    {"sset <fqn> <property> <value>", "writes a static property of a class", 3, 3, (*console).sset},
+		"del":     {"del <object>", "releases an object", 1, 1, (*console).del},
+		"objects": {"objects", "lists the objects obtained so far", 0, 0, (*console).list},
+		"stats":   {"stats", "prints the kernel's statistics", 0, 0, (*console).stats},
+		"trace":   {"trace on|off", "prints the messages exchanged with the kernel", 1, 1, (*console).trace},
+		"help":    {"help", "prints this help", 0, 0, (*console).help},
+		"quit":    {"quit", "exits the console", 0, 0, (*console).quit},
+	}
+}
+
+// execute runs the provided command line, printing its outcome, and returns
+// whether it succeeded. Blank lines and lines starting with # are ignored.
+func (c *console) execute(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return true
+	}
+
+	err := c.dispatch(line)
+	if err != nil {
+		fmt.Fprintf(c.out, "error: %v\n", err)
+		c.failed = true
+	}
+	return err == nil
+}
+
+func (c *console) dispatch(line string) error {
+	words, err := splitArgs(line)
+	if err != nil {
+		return err
+	}
+
+	name, args := words[0], words[1:]
+	cmd, found := commands[name]
+	if !found {
+		return fmt.Errorf("unknown command %q (type help for the list of commands)", name)
+	}
+	if len(args) < cmd.minArgs || (cmd.maxArgs >= 0 && len(args) > cmd.maxArgs) {
+		return fmt.Errorf("usage: %v", cmd.usage)
+	}
+	return cmd.run(c, args)
+}
+
+func (c *console) load(args []string) error {
+	path := unquote(args[0])
+	tarball, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	props, err := readPackageInfo(tarball)
+	if err != nil {
+		return fmt.Errorf("%v: %w", path, err)
+	}
+
+	res, err := c.client.Load(props, tarball)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "loaded %v@%v (%v types)\n", res.Assembly, props.Version, res.Types)
+	return nil
+}
+
+func (c *console) create(args []string) error {
+	values, err := c.values(args[1:])
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Create(kernel.CreateProps{FQN: protocol.FQN(args[0]), Arguments: values})
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]interface{}{"$jsii.byref": res.InstanceID})
+}
+
+func (c *console) invoke(args []string) error {
+	ref, err := c.object(args[0])
+	if err != nil {
+		return err
+	}
+	values, err := c.values(args[2:])
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Invoke(kernel.InvokeProps{ObjRef: ref, Method: args[1], Arguments: values})
+	if err != nil {
+		return err
+	}
+	return c.print(res.Result)
+}
+
+func (c *console) sinvoke(args []string) error {
+	values, err := c.values(args[2:])
+	if err != nil {
+		return err
+	}
+	res, err := c.client.SInvoke(kernel.StaticInvokeProps{FQN: protocol.FQN(args[0]), Method: args[1], Arguments: values})
+	if err != nil {
+		return err
+	}
+	return c.print(res.Result)
+}
+
+func (c *console) get(args []string) error {
+	ref, err := c.object(args[0])
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Get(kernel.GetProps{ObjRef: ref, Property: args[1]})
+	if err != nil {
+		return err
+	}
+	return c.print(res.Value)
+}
+
+func (c *console) sget(args []string) error {
+	res, err := c.client.SGet(kernel.StaticGetProps{FQN: protocol.FQN(args[0]), Property: args[1]})
+	if err != nil {
+		return err
+	}
+	return c.print(res.Value)
+}
+
+func (c *console) set(args []string) error {
+	ref, err := c.object(args[0])
+	if err != nil {
+		return err
+	}
+	value, err := c.value(args[2])
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Set(kernel.SetProps{ObjRef: ref, Property: args[1], Value: value})
+	return err
+}
+
+func (c *console) sset(args []string) error {
+	value, err := c.value(args[2])
+	if err != nil {
+		return err
+	}
+	_, err = c.client.SSet(kernel.StaticSetProps{FQN: protocol.FQN(args[0]), Property: args[1], Value: value})
+	return err
+}
+
+func (c *console) del(args []string) error {
+	ref, err := c.object(args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := c.client.Del(kernel.DelProps{ObjRef: ref}); err != nil {
+		return err
+	}
+	if index, found := c.labels[ref.InstanceID]; found {
+		delete(c.labels, ref.InstanceID)
+		c.objects[index] = protocol.ObjectRef{}
+	}
+	return nil
+}
+
+func (c *console) list(args []string) error {
+	for i, ref := range c.objects {
+		if ref.InstanceID == "" {
+			continue
+		}
+		fmt.Fprintf(c.out, "$%d\t%v", i+1, ref.InstanceID)
+		if len(ref.Interfaces) > 0 {
+			interfaces := make([]string, len(ref.Interfaces))
+			for i, iface := range ref.Interfaces {
+				interfaces[i] = string(iface)
+			}
+			sort.Strings(interfaces)
+			fmt.Fprintf(c.out, "\t(implements %v)", strings.Join(interfaces, ", "))
+		}
+		fmt.Fprintln(c.out)
+	}
+	return nil
+}
+
+func (c *console) stats(args []string) error {
+	res, err := c.client.Stats()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "objects: %v\n", res.ObjectCount)
+	return nil
+}
+
+func (c *console) trace(args []string) error {
+	switch args[0] {
+	case "on":
+		c.client.SetTrace(c.out)
+	case "off":
+		c.client.SetTrace(nil)
+	default:
+		return fmt.Errorf("usage: %v", commands["trace"].usage)
+	}
+	return nil
+}
+
+func (c *console) help(args []string) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.out, "  %-36v %v\n", commands[name].usage, commands[name].description)
+	}
+	fmt.Fprintln(c.out, "Objects are designated by their label (e.g: $1) or instance ID. Arguments and")
+	fmt.Fprintln(c.out, "values are JSON values, object labels, or unquoted strings.")
+	return nil
+}
+
+func (c *console) quit(args []string) error {
+	c.done = true
+	return nil
+}
+
+// object resolves an object label (e.g: $1) or instance ID into a reference.
+func (c *console) object(arg string) (protocol.ObjectRef, error) {
+	if strings.HasPrefix(arg, "$") {
+		index, err := strconv.Atoi(arg[1:])
+		if err != nil || index < 1 || index > len(c.objects) || c.objects[index-1].InstanceID == "" {
+			return protocol.ObjectRef{}, fmt.Errorf("no such object: %v", arg)
+		}
+		return c.objects[index-1], nil
+	}
+	ref := protocol.ObjectRef{InstanceID: arg}
+	if _, ok := ref.TryTypeFQN(); !ok {
+		return ref, fmt.Errorf("not an object label or instance ID: %v", arg)
+	}
+	return ref, nil
+}
+
+// record adds the provided reference to the objects, unless it is already
+// known, and returns its label.
+func (c *console) record(ref protocol.ObjectRef) string {
+	index, found := c.labels[ref.InstanceID]
+	if !found {
+		index = len(c.objects)
+		c.objects = append(c.objects, ref)
+		c.labels[ref.InstanceID] = index
+	} else {
+		c.objects[index].Interfaces = mergeFQNs(c.objects[index].Interfaces, ref.Interfaces)
+	}
+	return fmt.Sprintf("$%d", index+1)
+}
+
+func mergeFQNs(into []protocol.FQN, from []protocol.FQN) []protocol.FQN {
+	for _, fqn := range from {
+		found := false
+		for _, existing := range into {
+			found = found || existing == fqn
+		}
+		if !found {
+			into = append(into, fqn)
+		}
+	}
+	return into
+}
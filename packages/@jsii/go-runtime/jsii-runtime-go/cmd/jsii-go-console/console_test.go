@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+)
+
+// stubClient records the requests it receives, and responds with canned
+// results.
+type stubClient struct {
+	requests []interface{}
+	trace    io.Writer
+}
+
+func (s *stubClient) Load(props kernel.LoadProps, tarball []byte) (kernel.LoadResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.LoadResponse{Assembly: props.Name, Types: 42}, nil
+}
+
+func (s *stubClient) Create(props kernel.CreateProps) (kernel.CreateResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.CreateResponse{InstanceID: string(props.FQN) + "@10000"}, nil
+}
+
+func (s *stubClient) Del(props kernel.DelProps) (kernel.DelResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.DelResponse{}, nil
+}
+
+func (s *stubClient) Invoke(props kernel.InvokeProps) (kernel.InvokeResponse, error) {
+	s.requests = append(s.requests, props)
+	if props.Method == "fail" {
+		return kernel.InvokeResponse{}, fmt.Errorf("Boom!")
+	}
+	return kernel.InvokeResponse{Result: []interface{}{
+		json.Number("1"),
+		map[string]interface{}{"$jsii.byref": "test.Other@10001", "$jsii.interfaces": []interface{}{"test.IOther"}},
+	}}, nil
+}
+
+func (s *stubClient) SInvoke(props kernel.StaticInvokeProps) (kernel.InvokeResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.InvokeResponse{Result: "static"}, nil
+}
+
+func (s *stubClient) Get(props kernel.GetProps) (kernel.GetResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.GetResponse{Value: map[string]interface{}{"$jsii.byref": props.ObjRef.InstanceID}}, nil
+}
+
+func (s *stubClient) SGet(props kernel.StaticGetProps) (kernel.GetResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.GetResponse{Value: nil}, nil
+}
+
+func (s *stubClient) Set(props kernel.SetProps) (kernel.SetResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.SetResponse{}, nil
+}
+
+func (s *stubClient) SSet(props kernel.StaticSetProps) (kernel.SetResponse, error) {
+	s.requests = append(s.requests, props)
+	return kernel.SetResponse{}, nil
+}
+
+func (s *stubClient) Stats() (kernel.StatsResponse, error) {
+	return kernel.StatsResponse{ObjectCount: 2}, nil
+}
+
+func (s *stubClient) SetTrace(out io.Writer) {
+	s.trace = out
+}
+
+func TestSplitArgs(t *testing.T) {
+	words, err := splitArgs(`invoke $1 add 1 "two words" {"key": [1, "}"]} plain`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"invoke", "$1", "add", "1", `"two words"`, `{"key": [1, "}"]}`, "plain"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected %#v, got %#v", expected, words)
+	}
+
+	for _, line := range []string{`invoke "open`, `create [1, 2`, `create ]`} {
+		if _, err := splitArgs(line); err == nil {
+			t.Errorf("expected an error for %v", line)
+		}
+	}
+}
+
+func TestConsole(t *testing.T) {
+	var (
+		client = &stubClient{}
+		out    bytes.Buffer
+	)
+	console := newConsole(client, &out)
+	run := func(line string) string {
+		out.Reset()
+		console.execute(line)
+		return out.String()
+	}
+
+	if output := run(`create test.Thing 1 "two"`); output != "$1 = test.Thing@10000\n" {
+		t.Errorf("unexpected create output: %q", output)
+	}
+	if output := run(`invoke $1 run $1 {"a":1} text`); output != "$2 = test.Other@10001\n[\n  1,\n  \"$2\"\n]\n" {
+		t.Errorf("unexpected invoke output: %q", output)
+	}
+	invoked := client.requests[1].(kernel.InvokeProps)
+	if invoked.ObjRef.InstanceID != "test.Thing@10000" || invoked.Method != "run" {
+		t.Errorf("unexpected invoke request: %#v", invoked)
+	}
+	if !reflect.DeepEqual(invoked.Arguments[1:], []interface{}{map[string]interface{}{"a": json.Number("1")}, "text"}) {
+		t.Errorf("unexpected arguments: %#v", invoked.Arguments)
+	}
+	if output := run(`get test.Other@10001 self`); output != "$2 = test.Other@10001\n" {
+		t.Errorf("expected known objects to keep their label, got %q", output)
+	}
+
+	if output := run("objects"); output != "$1\ttest.Thing@10000\n$2\ttest.Other@10001\t(implements test.IOther)\n" {
+		t.Errorf("unexpected objects output: %q", output)
+	}
+	run("del $1")
+	if output := run("objects"); output != "$2\ttest.Other@10001\t(implements test.IOther)\n" {
+		t.Errorf("expected deleted objects not to be listed, got %q", output)
+	}
+	if console.failed {
+		t.Errorf("no command was expected to fail")
+	}
+
+	for line, expected := range map[string]string{
+		"invoke $1 run":       "error: no such object: $1\n",
+		"invoke $2 fail":      "error: Boom!\n",
+		"unknown":             "error: unknown command \"unknown\" (type help for the list of commands)\n",
+		"set $2 size":         "error: usage: set <object> <property> <value>\n",
+		"get not-an-object x": "error: not an object label or instance ID: not-an-object\n",
+	} {
+		if output := run(line); output != expected {
+			t.Errorf("expected %q for %v, got %q", expected, line, output)
+		}
+	}
+
+	run("trace on")
+	if client.trace != &out {
+		t.Error("expected tracing to be enabled")
+	}
+	run("trace off")
+	if client.trace != nil {
+		t.Error("expected tracing to be disabled")
+	}
+
+	if run("quit"); !console.done {
+		t.Error("expected quit to end the session")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	archive := tar.NewWriter(gz)
+	manifest := []byte(`{"name":"@scope/lib","version":"1.2.3"}`)
+	archive.WriteHeader(&tar.Header{Name: "package/package.json", Mode: 0644, Size: int64(len(manifest))})
+	archive.Write(manifest)
+	archive.Close()
+	gz.Close()
+
+	file, err := ioutil.TempFile("", "lib.*.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.Write(buffer.Bytes())
+	file.Close()
+
+	var out bytes.Buffer
+	client := &stubClient{}
+	if !newConsole(client, &out).execute(fmt.Sprintf("load %q", file.Name())) {
+		t.Fatalf("load failed: %v", out.String())
+	}
+	if !strings.HasPrefix(out.String(), "loaded @scope/lib@1.2.3") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if props := client.requests[0].(kernel.LoadProps); props.Name != "@scope/lib" || props.Version != "1.2.3" {
+		t.Errorf("unexpected load request: %#v", props)
+	}
+}
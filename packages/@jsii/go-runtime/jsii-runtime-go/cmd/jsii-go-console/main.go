@@ -0,0 +1,74 @@
+// Command jsii-go-console is an interactive console for the @jsii/kernel
+// process, as it is started and driven by the jsii runtime for go. It loads the
+// assembly tarballs provided as arguments, then reads commands from the
+// standard input, one per line, which create objects, call methods, get and set
+// properties, and inspect the objects obtained so far. Type "help" for the list
+// of commands.
+//
+// Usage:
+//
+//	jsii-go-console [-trace] [tarball ...]
+//
+// The kernel process is started as by the runtime, so the JSII_RUNTIME
+// environment variable can be used to run a custom one.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/mattn/go-isatty"
+)
+
+func main() {
+	trace := flag.Bool("trace", false, "print the messages exchanged with the kernel")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [-trace] [tarball ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	os.Exit(run(*trace, flag.Args()))
+}
+
+func run(trace bool, tarballs []string) int {
+	defer kernel.CloseClient()
+
+	console := newConsole(kernel.GetClient(), os.Stdout)
+	if trace {
+		console.execute("trace on")
+	}
+	for _, tarball := range tarballs {
+		if !console.execute(fmt.Sprintf("load %q", tarball)) {
+			return 1
+		}
+	}
+
+	interactive := isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+	scanner := bufio.NewScanner(os.Stdin)
+	// Command lines may carry large JSON arguments.
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for {
+		if interactive {
+			fmt.Print("jsii> ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+		console.execute(scanner.Text())
+		if console.done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if console.failed && !interactive {
+		return 1
+	}
+	return 0
+}
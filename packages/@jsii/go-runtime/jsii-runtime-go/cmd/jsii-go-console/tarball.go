@@ -0,0 +1,48 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+)
+
+// readPackageInfo reads the name and version of an npm package from its
+// package.json file in the provided tarball.
+func readPackageInfo(tarball []byte) (props kernel.LoadProps, err error) {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return
+	}
+	defer gz.Close()
+
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return props, fmt.Errorf("package/package.json not found")
+		}
+		if err != nil {
+			return props, err
+		}
+		if header.Name != "package/package.json" {
+			continue
+		}
+
+		var manifest struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if err := json.NewDecoder(archive).Decode(&manifest); err != nil {
+			return props, fmt.Errorf("invalid package/package.json: %w", err)
+		}
+		if manifest.Name == "" || manifest.Version == "" {
+			return props, fmt.Errorf("package/package.json has no name or version")
+		}
+		return kernel.LoadProps{Name: manifest.Name, Version: manifest.Version}, nil
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+// splitArgs splits a command line into words, separated by white space. JSON
+// strings, arrays and objects are kept whole, even if they contain spaces.
+func splitArgs(line string) ([]string, error) {
+	var (
+		words    []string
+		current  strings.Builder
+		depth    int
+		inString bool
+		escaped  bool
+	)
+	for _, r := range line {
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == '"' {
+				inString = false
+			}
+		case r == '"':
+			inString = true
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			if depth--; depth < 0 {
+				return nil, fmt.Errorf("unbalanced %q", r)
+			}
+		case depth == 0 && unicode.IsSpace(r):
+			if current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if inString {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("unterminated array or object")
+	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// unquote returns the string a word denotes, which may be a JSON string (e.g:
+// for file paths that contain spaces).
+func unquote(word string) string {
+	var value string
+	if strings.HasPrefix(word, `"`) && json.Unmarshal([]byte(word), &value) == nil {
+		return value
+	}
+	return word
+}
+
+// values parses the provided arguments (see value).
+func (c *console) values(args []string) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		value, err := c.value(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// value parses an argument, which is either an object label (e.g: $1), a JSON
+// value, or otherwise an unquoted string.
+func (c *console) value(arg string) (interface{}, error) {
+	if strings.HasPrefix(arg, "$") {
+		return c.object(arg)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(arg))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return arg, nil
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return arg, nil
+	}
+	return value, nil
+}
+
+// print renders a value received from the kernel. Object references are
+// recorded, and rendered as their label.
+func (c *console) print(value interface{}) error {
+	known := len(c.objects)
+
+	if ref, ok := asObjectRef(value); ok {
+		fmt.Fprintf(c.out, "%v = %v\n", c.record(ref), ref.InstanceID)
+		return nil
+	}
+
+	rendered := c.render(value)
+	for i := known; i < len(c.objects); i++ {
+		fmt.Fprintf(c.out, "$%d = %v\n", i+1, c.objects[i].InstanceID)
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(rendered); err != nil {
+		return err
+	}
+	_, err := c.out.Write(buffer.Bytes())
+	return err
+}
+
+// render replaces the object references in the provided value by labels.
+func (c *console) render(value interface{}) interface{} {
+	if ref, ok := asObjectRef(value); ok {
+		return c.record(ref)
+	}
+	switch value := value.(type) {
+	case []interface{}:
+		rendered := make([]interface{}, len(value))
+		for i, item := range value {
+			rendered[i] = c.render(item)
+		}
+		return rendered
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			rendered[key] = c.render(item)
+		}
+		return rendered
+	default:
+		return value
+	}
+}
+
+// asObjectRef returns the object reference the provided wire value denotes,
+// if it is one.
+func asObjectRef(value interface{}) (ref protocol.ObjectRef, ok bool) {
+	data, isMap := value.(map[string]interface{})
+	if !isMap {
+		return
+	}
+	if ref.InstanceID, ok = data["$jsii.byref"].(string); !ok {
+		return
+	}
+	if interfaces, isList := data["$jsii.interfaces"].([]interface{}); isList {
+		for _, iface := range interfaces {
+			if fqn, isString := iface.(string); isString {
+				ref.Interfaces = append(ref.Interfaces, protocol.FQN(fqn))
+			}
+		}
+	}
+	return
+}
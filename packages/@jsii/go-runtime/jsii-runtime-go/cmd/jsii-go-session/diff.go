@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// edit is an operation of a line-based diff.
+type edit struct {
+	op   byte // ' ' (kept), '-' (removed) or '+' (added)
+	line string
+	// a and b are the (0-based) indexes of the line in either sequence, or
+	// the index of the next line for removed and added lines respectively.
+	a, b int
+}
+
+// diffLines computes the shortest edit script turning a into b, using the
+// Myers algorithm. Only the diagonals reached at each step are kept for
+// backtracking, so the trace grows with the square of the number of edits
+// rather than with that of the number of lines.
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds the furthest x reached on diagonals -d to d before step d.
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int, depth int) []edit {
+	x, y := len(a), len(b)
+	var edits []edit
+	for d := depth; d > 0; d-- {
+		// Diagonal k of step d is at index d+k of its snapshot.
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, edit{' ', a[x], x, y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{'+', b[y], x, y})
+		} else {
+			x--
+			edits = append(edits, edit{'-', a[x], x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		edits = append(edits, edit{' ', a[x], x, y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// printDiff prints the differences between the call trees of two sessions,
+// as a unified diff with the provided number of context lines. Objects are
+// named after their order of appearance, so instance IDs do not cause
+// spurious differences. It returns whether the sessions differ.
+func printDiff(out io.Writer, nameA string, a *session, nameB string, b *session, context int) bool {
+	linesA, callsA := newRenderer(a, true, 0).lines(false)
+	linesB, callsB := newRenderer(b, true, 0).lines(false)
+	edits := diffLines(linesA, linesB)
+
+	first := -1
+	for i, e := range edits {
+		if e.op != ' ' {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return false
+	}
+
+	fmt.Fprintf(out, "--- %v\n+++ %v\n", nameA, nameB)
+	e := edits[first]
+	fmt.Fprintf(out, "# first difference at call %d of %v, call %d of %v\n", callAt(callsA, e.a), nameA, callAt(callsB, e.b), nameB)
+
+	for start := 0; start < len(edits); {
+		// Find the next change, and the extent of its hunk.
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		from := start - context
+		if from < 0 {
+			from = 0
+		}
+		end, kept := start, 0
+		for end < len(edits) && kept <= 2*context {
+			if edits[end].op == ' ' {
+				kept++
+			} else {
+				kept = 0
+			}
+			end++
+		}
+		// Only keep the context lines that follow the last change.
+		if end = end - kept + context; end > len(edits) {
+			end = len(edits)
+		}
+
+		countA, countB := 0, 0
+		for _, e := range edits[from:end] {
+			if e.op != '+' {
+				countA++
+			}
+			if e.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", edits[from].a+1, countA, edits[from].b+1, countB)
+		for _, e := range edits[from:end] {
+			fmt.Fprintf(out, "%c%v\n", e.op, e.line)
+		}
+		start = end
+	}
+	return true
+}
+
+// callAt returns the (1-based) number of the call rendered on the line with the
+// provided index, given the index of the call of each line. Lines past the end
+// are attributed to the call that would follow the last one.
+func callAt(calls []int, line int) int {
+	if line < len(calls) {
+		return calls[line] + 1
+	}
+	if len(calls) == 0 {
+		return 1
+	}
+	return calls[len(calls)-1] + 2
+}
@@ -0,0 +1,86 @@
+// Command jsii-go-session inspects the messages exchanged with the
+// @jsii/kernel process during a session, as recorded by the jsii runtime for go
+// when the JSII_TRACE_FILE environment variable is set (or as printed by the
+// trace command of jsii-go-console).
+//
+// Usage:
+//
+//	jsii-go-session tree [-timings] [-max-value n] <trace>
+//	jsii-go-session summary <trace>
+//	jsii-go-session diff [-context n] <trace> <trace>
+//
+// The tree command prints the session as a call tree, in which callbacks are
+// nested under the requests that triggered them, and the requests sent while
+// fulfilling a callback are nested under it. The summary command prints the
+// number of calls of each kind, along with their timings. The diff command
+// compares the call trees of two sessions, naming objects after their order of
+// appearance so that differing instance IDs do not matter; it exits with status
+// 1 if the sessions differ. A trace of "-" is read from the standard input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: jsii-go-session tree|summary|diff [flags] <trace> ...")
+		return 2
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	timings := flags.Bool("timings", false, "print the duration of calls")
+	maxValue := flags.Int("max-value", 120, "truncate values longer than this (0 for no limit)")
+	context := flags.Int("context", 3, "number of unchanged calls to show around differences")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	arity := map[string]int{"tree": 1, "summary": 1, "diff": 2}[args[0]]
+	if arity == 0 || flags.NArg() != arity {
+		fmt.Fprintf(stderr, "usage: jsii-go-session tree|summary|diff [flags] <trace> ...\n")
+		return 2
+	}
+
+	sessions := make([]*session, arity)
+	for i, path := range flags.Args() {
+		s, err := readSession(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v: %v\n", path, err)
+			return 2
+		}
+		sessions[i] = s
+	}
+
+	switch args[0] {
+	case "tree":
+		printTree(stdout, sessions[0], *timings, *maxValue)
+	case "summary":
+		printSummary(stdout, sessions[0])
+	case "diff":
+		if printDiff(stdout, flags.Arg(0), sessions[0], flags.Arg(1), sessions[1], *context) {
+			return 1
+		}
+	}
+	return 0
+}
+
+func readSession(path string) (*session, error) {
+	if path == "-" {
+		return parseSession(os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseSession(file)
+}
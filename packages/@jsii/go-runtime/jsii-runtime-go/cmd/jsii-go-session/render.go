@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+// renderer renders the calls of a session as text.
+type renderer struct {
+	session *session
+	// normalize makes renderings of distinct sessions comparable, by naming
+	// objects after their order of appearance instead of their instance ID.
+	normalize bool
+	// maxValue is the length beyond which values are truncated, if positive.
+	maxValue int
+
+	ordinals map[string]string
+	counts   map[protocol.FQN]int
+}
+
+func newRenderer(s *session, normalize bool, maxValue int) *renderer {
+	return &renderer{session: s, normalize: normalize, maxValue: maxValue, ordinals: make(map[string]string), counts: make(map[protocol.FQN]int)}
+}
+
+// object renders an instance ID, along with the interfaces it implements if
+// its class is not informative.
+func (r *renderer) object(instanceID string) string {
+	ref := protocol.ObjectRef{InstanceID: instanceID}
+	name := instanceID
+	if r.normalize {
+		if ordinal, found := r.ordinals[instanceID]; found {
+			name = ordinal
+		} else if fqn, ok := ref.TryTypeFQN(); ok {
+			r.counts[fqn]++
+			name = fmt.Sprintf("%v#%d", fqn, r.counts[fqn])
+			r.ordinals[instanceID] = name
+		}
+	}
+	if interfaces := r.session.objects[instanceID]; len(interfaces) > 0 {
+		names := make([]string, len(interfaces))
+		for i, iface := range interfaces {
+			names[i] = string(iface)
+		}
+		sort.Strings(names)
+		name = fmt.Sprintf("%v<%v>", name, strings.Join(names, ", "))
+	}
+	return name
+}
+
+// value renders a wire value.
+func (r *renderer) value(value interface{}) string {
+	rendered := r.format(value)
+	if r.maxValue > 0 && len(rendered) > r.maxValue {
+		rendered = rendered[:r.maxValue] + "…"
+	}
+	return rendered
+}
+
+// raw renders a wire value encoded as JSON.
+func (r *renderer) raw(data json.RawMessage) string {
+	if len(data) == 0 {
+		return "undefined"
+	}
+	var value interface{}
+	if err := decode(data, &value); err != nil {
+		return string(data)
+	}
+	return r.value(value)
+}
+
+func (r *renderer) values(values []interface{}) string {
+	rendered := make([]string, len(values))
+	for i, value := range values {
+		rendered[i] = r.value(value)
+	}
+	return strings.Join(rendered, ", ")
+}
+
+func (r *renderer) format(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = r.format(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		if id, ok := value["$jsii.byref"].(string); ok {
+			return r.object(id)
+		}
+		if date, ok := value["$jsii.date"].(string); ok {
+			return fmt.Sprintf("Date(%v)", date)
+		}
+		if enum, ok := value["$jsii.enum"].(string); ok {
+			return enum
+		}
+		if data, ok := value["$jsii.map"]; ok {
+			return r.format(data)
+		}
+		if strct, ok := value["$jsii.struct"].(map[string]interface{}); ok {
+			return fmt.Sprintf("%v%v", strct["fqn"], r.format(strct["data"]))
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			entries[i] = fmt.Sprintf("%v: %v", key, r.format(value[key]))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// describe renders a call on a single line.
+func (r *renderer) describe(c *call) string {
+	var text string
+	if c.Callback != nil {
+		text = r.describeCallback(c.Callback)
+	} else {
+		text = r.describeRequest(c.Request)
+	}
+
+	switch {
+	case c.Error != "":
+		message := strings.SplitN(c.Error, "\n", 2)[0]
+		text = fmt.Sprintf("%v !! %v", text, message)
+	case !c.Done:
+		text += " (incomplete)"
+	case c.Callback != nil && c.Callback.Set == nil:
+		text = fmt.Sprintf("%v => %v", text, r.raw(c.Result))
+	case c.Request != nil && c.Request.API != "set" && c.Request.API != "sset" && c.Request.API != "del":
+		text = fmt.Sprintf("%v => %v", text, r.result(c))
+	}
+	return text
+}
+
+func (r *renderer) describeCallback(cb *protocol.Callback) string {
+	switch {
+	case cb.Invoke != nil:
+		return fmt.Sprintf("callback %v.%v(%v) [%v]", r.object(cb.Invoke.ObjRef.InstanceID), cb.Invoke.Method, r.values(cb.Invoke.Arguments), cb.Cookie)
+	case cb.Get != nil:
+		return fmt.Sprintf("callback get %v.%v [%v]", r.object(cb.Get.ObjRef.InstanceID), cb.Get.Property, cb.Cookie)
+	case cb.Set != nil:
+		return fmt.Sprintf("callback set %v.%v = %v [%v]", r.object(cb.Set.ObjRef.InstanceID), cb.Set.Property, r.value(cb.Set.Value), cb.Cookie)
+	default:
+		return fmt.Sprintf("callback %v", cb.CallbackID)
+	}
+}
+
+func (r *renderer) describeRequest(req *protocol.Request) string {
+	switch props := req.Props.(type) {
+	case *protocol.LoadProps:
+		return fmt.Sprintf("load %v@%v", props.Name, props.Version)
+	case *protocol.CreateProps:
+		return fmt.Sprintf("create %v(%v)", props.FQN, r.values(props.Arguments))
+	case *protocol.DelProps:
+		return fmt.Sprintf("del %v", r.object(props.ObjRef.InstanceID))
+	case *protocol.InvokeProps:
+		return fmt.Sprintf("invoke %v.%v(%v)", r.object(props.ObjRef.InstanceID), props.Method, r.values(props.Arguments))
+	case *protocol.StaticInvokeProps:
+		return fmt.Sprintf("sinvoke %v.%v(%v)", props.FQN, props.Method, r.values(props.Arguments))
+	case *protocol.GetProps:
+		return fmt.Sprintf("get %v.%v", r.object(props.ObjRef.InstanceID), props.Property)
+	case *protocol.StaticGetProps:
+		return fmt.Sprintf("sget %v.%v", props.FQN, props.Property)
+	case *protocol.SetProps:
+		return fmt.Sprintf("set %v.%v = %v", r.object(props.ObjRef.InstanceID), props.Property, r.value(props.Value))
+	case *protocol.StaticSetProps:
+		return fmt.Sprintf("sset %v.%v = %v", props.FQN, props.Property, r.value(props.Value))
+	case *protocol.BeginProps:
+		method := ""
+		if props.Method != nil {
+			method = *props.Method
+		}
+		return fmt.Sprintf("begin %v.%v(%v)", r.object(props.ObjRef.InstanceID), method, r.values(props.Arguments))
+	case *protocol.EndProps:
+		return fmt.Sprintf("end %v", stringOf(props.PromiseID))
+	case *protocol.CompleteProps:
+		if props.Error != nil {
+			return fmt.Sprintf("complete %v !! %v", stringOf(props.CallbackID), strings.SplitN(*props.Error, "\n", 2)[0])
+		}
+		return fmt.Sprintf("complete %v = %v", stringOf(props.CallbackID), r.value(props.Result))
+	case *protocol.NamingProps:
+		return fmt.Sprintf("naming %v", props.Assembly)
+	case nil:
+		return req.API
+	default:
+		return fmt.Sprintf("%v %v", req.API, r.value(props))
+	}
+}
+
+// result renders the result of a successful request.
+func (r *renderer) result(c *call) string {
+	var value interface{}
+	if err := decode(c.Result, &value); err != nil {
+		return string(c.Result)
+	}
+	data, _ := value.(map[string]interface{})
+	switch c.Request.API {
+	case "create":
+		return r.value(value)
+	case "invoke", "sinvoke", "end":
+		return r.value(data["result"])
+	case "get", "sget":
+		return r.value(data["value"])
+	case "begin":
+		return fmt.Sprint(data["promiseid"])
+	case "load":
+		return fmt.Sprintf("%v types", data["types"])
+	case "callbacks":
+		callbacks, _ := data["callbacks"].([]interface{})
+		return fmt.Sprintf("%d pending", len(callbacks))
+	default:
+		return r.value(value)
+	}
+}
+
+func stringOf(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+// lines renders the call tree, one call per line, indented by depth. It also
+// returns the index of the call rendered on each line, calls being numbered in
+// the order they were made (i.e: depth first).
+func (r *renderer) lines(timings bool) (lines []string, calls []int) {
+	count := 0
+	var walk func(children []*call, depth int)
+	walk = func(children []*call, depth int) {
+		for _, c := range children {
+			index := count
+			count++
+			line := strings.Repeat("  ", depth) + r.describe(c)
+			if duration := c.Duration(); timings && duration > 0 {
+				line = fmt.Sprintf("%v  (%v)", line, duration)
+			}
+			lines = append(lines, line)
+			calls = append(calls, index)
+			walk(c.Children, depth+1)
+		}
+	}
+	walk(r.session.Calls, 0)
+	return
+}
+
+// printTree prints the call tree of the session.
+func printTree(out io.Writer, s *session, timings bool, maxValue int) {
+	if s.Runtime != "" {
+		fmt.Fprintf(out, "# %v\n", s.Runtime)
+	}
+	lines, _ := newRenderer(s, false, maxValue).lines(timings)
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+}
+
+// stats accumulates the counts and timings of calls of a kind.
+type stats struct {
+	count, errors int
+	total, max    time.Duration
+	timed         int
+}
+
+func (s *stats) add(c *call) {
+	s.count++
+	if c.Error != "" {
+		s.errors++
+	}
+	if duration := c.Duration(); duration > 0 {
+		s.timed++
+		s.total += duration
+		if duration > s.max {
+			s.max = duration
+		}
+	}
+}
+
+func (s *stats) row(name string) string {
+	if s.timed == 0 {
+		return fmt.Sprintf("  %v\t%d\t%d\t-\t-\t-\t", name, s.count, s.errors)
+	}
+	return fmt.Sprintf("  %v\t%d\t%d\t%v\t%v\t%v\t", name, s.count, s.errors, s.total, s.total/time.Duration(s.timed), s.max)
+}
+
+// printSummary prints the counts and timings of the calls of the session.
+func printSummary(out io.Writer, s *session) {
+	requests := make(map[string]*stats)
+	callbacks := make(map[string]*stats)
+	created := make(map[protocol.FQN]int)
+
+	var walk func(calls []*call)
+	walk = func(calls []*call) {
+		for _, c := range calls {
+			kinds, kind := requests, ""
+			if c.Callback != nil {
+				kinds = callbacks
+				switch {
+				case c.Callback.Invoke != nil:
+					kind = "invoke"
+				case c.Callback.Get != nil:
+					kind = "get"
+				default:
+					kind = "set"
+				}
+			} else {
+				kind = c.Request.API
+				if create, ok := c.Request.Props.(*protocol.CreateProps); ok {
+					created[create.FQN]++
+				}
+			}
+			if kinds[kind] == nil {
+				kinds[kind] = &stats{}
+			}
+			kinds[kind].add(c)
+			walk(c.Children)
+		}
+	}
+	walk(s.Calls)
+
+	if s.Runtime != "" {
+		fmt.Fprintf(out, "runtime: %v\n", s.Runtime)
+	}
+	if !s.Start.IsZero() {
+		fmt.Fprintf(out, "duration: %v\n", s.End.Sub(s.Start))
+	}
+
+	table := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	for _, section := range []struct {
+		title string
+		kinds map[string]*stats
+	}{{"requests", requests}, {"callbacks", callbacks}} {
+		fmt.Fprintf(table, "%v\tcount\terrors\ttotal\tmean\tmax\t\n", section.title)
+		var all stats
+		for _, name := range sortedKeys(section.kinds) {
+			kind := section.kinds[name]
+			fmt.Fprintln(table, kind.row(name))
+			all.count += kind.count
+			all.errors += kind.errors
+			all.total += kind.total
+			all.timed += kind.timed
+			if kind.max > all.max {
+				all.max = kind.max
+			}
+		}
+		fmt.Fprintln(table, all.row("all"))
+	}
+	table.Flush()
+
+	if len(created) > 0 {
+		fmt.Fprintln(out, "objects created:")
+		names := make([]string, 0, len(created))
+		for fqn := range created {
+			names = append(names, string(fqn))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %v: %d\n", name, created[protocol.FQN(name)])
+		}
+	}
+}
+
+func sortedKeys(m map[string]*stats) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+// call is a node of a session's call tree: either a request sent to the
+// kernel, or a callback sent by the kernel to fulfill a request. The children
+// of a request are the callbacks it triggered, and those of a callback are the
+// requests sent while fulfilling it.
+type call struct {
+	// Request is set for requests to the kernel.
+	Request *protocol.Request
+	// Callback is set for callbacks from the kernel.
+	Callback *protocol.Callback
+
+	// Result is the result of a successful call, as JSON.
+	Result json.RawMessage
+	// Error is the error message of a failed call.
+	Error string
+	// Done is true once the call has completed.
+	Done bool
+
+	// Start and End are the times the call was traced at, if known.
+	Start, End time.Time
+
+	Children []*call
+
+	// line is the line of the trace the call starts at.
+	line int
+}
+
+// Duration returns how long the call took, or 0 if unknown.
+func (c *call) Duration() time.Duration {
+	if c.Start.IsZero() || c.End.IsZero() {
+		return 0
+	}
+	return c.End.Sub(c.Start)
+}
+
+// waiting returns true if this request is waiting for a callback to complete.
+func (c *call) waiting() bool {
+	if len(c.Children) == 0 {
+		return false
+	}
+	return !c.Children[len(c.Children)-1].Done
+}
+
+// session is a parsed trace of the messages exchanged with the kernel.
+type session struct {
+	// Runtime identifies the kernel, as announced by its handshake.
+	Runtime string
+	// Calls are the top-level requests, in order.
+	Calls []*call
+	// Start and End are the times of the first and last message, if known.
+	Start, End time.Time
+	// objects associates instance IDs with the interfaces they implement.
+	objects map[string][]protocol.FQN
+}
+
+// parser builds a session from the lines of a trace.
+type parser struct {
+	session *session
+	// contexts is the stack of pending callbacks, innermost last. Requests sent
+	// while a callback is pending are its children.
+	contexts []*call
+	// pending are the requests that have not been responded to yet.
+	pending []*call
+	// callbacks associates the ID of pending callbacks with them.
+	callbacks map[string]*call
+	line      int
+}
+
+// parseSession reads a trace, as written by the runtime when JSII_TRACE_FILE
+// is set, or by protocol.Conn.SetTrace.
+func parseSession(r io.Reader) (*session, error) {
+	p := &parser{
+		session:   &session{objects: make(map[string][]protocol.FQN)},
+		callbacks: make(map[string]*call),
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		p.line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		line, err := protocol.ParseTraceLine(scanner.Text())
+		if err == nil {
+			err = p.add(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+	}
+	return p.session, scanner.Err()
+}
+
+func (p *parser) add(line protocol.TraceLine) error {
+	if !line.Time.IsZero() {
+		if p.session.Start.IsZero() {
+			p.session.Start = line.Time
+		}
+		p.session.End = line.Time
+	}
+	if line.Sent {
+		return p.sent(line)
+	}
+	return p.received(line)
+}
+
+func (p *parser) sent(line protocol.TraceLine) error {
+	var message struct {
+		API      *string                  `json:"api"`
+		Complete *protocol.CallbackResult `json:"complete"`
+		Exit     *int                     `json:"exit"`
+	}
+	if err := json.Unmarshal(line.Message, &message); err != nil {
+		return err
+	}
+
+	switch {
+	case message.API != nil:
+		var request protocol.Request
+		if err := json.Unmarshal(line.Message, &request); err != nil {
+			return err
+		}
+		c := &call{Request: &request, Start: line.Time, line: p.line}
+		if len(p.contexts) > 0 {
+			parent := p.contexts[len(p.contexts)-1]
+			parent.Children = append(parent.Children, c)
+		} else {
+			p.session.Calls = append(p.session.Calls, c)
+		}
+		p.pending = append(p.pending, c)
+
+	case message.Complete != nil:
+		cb, found := p.callbacks[message.Complete.CallbackID]
+		if !found {
+			return fmt.Errorf("completion of unknown callback %v", message.Complete.CallbackID)
+		}
+		delete(p.callbacks, message.Complete.CallbackID)
+		cb.Done, cb.End, cb.Error = true, line.Time, message.Complete.Error
+		if message.Complete.Result != nil {
+			cb.Result, _ = json.Marshal(message.Complete.Result)
+		}
+		for i := len(p.contexts) - 1; i >= 0; i-- {
+			if p.contexts[i] == cb {
+				p.contexts = append(p.contexts[:i], p.contexts[i+1:]...)
+				break
+			}
+		}
+
+	case message.Exit != nil:
+		// The end of the session, nothing to record.
+
+	default:
+		return fmt.Errorf("unexpected message sent: %s", line.Message)
+	}
+	return nil
+}
+
+func (p *parser) received(line protocol.TraceLine) error {
+	var hello protocol.Hello
+	if err := json.Unmarshal(line.Message, &hello); err == nil && hello.Hello != "" {
+		p.session.Runtime = hello.Hello
+		return nil
+	}
+
+	var res struct {
+		protocol.Response
+		Ok json.RawMessage `json:"ok"`
+	}
+	if err := json.Unmarshal(line.Message, &res); err != nil {
+		return err
+	}
+
+	// Responses are for the oldest pending request that is not waiting for a
+	// callback to complete, starting from the innermost context. This accounts
+	// for pipelined requests, which the kernel processes in order.
+	target := p.target()
+	if target == nil {
+		return fmt.Errorf("response without a pending request: %s", line.Message)
+	}
+
+	if res.Callback != nil {
+		cb := &call{Callback: res.Callback, Start: line.Time, line: p.line}
+		target.Children = append(target.Children, cb)
+		p.contexts = append(p.contexts, cb)
+		p.callbacks[res.Callback.CallbackID] = cb
+		p.recordRefs(res.Callback.Invoke, res.Callback.Get, res.Callback.Set)
+		return nil
+	}
+
+	target.Done, target.End = true, line.Time
+	if res.Error != nil {
+		target.Error = *res.Error
+	} else {
+		target.Result = res.Ok
+		p.recordResult(target)
+	}
+	for i, pending := range p.pending {
+		if pending == target {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// target returns the request a response is for.
+func (p *parser) target() *call {
+	for depth := len(p.contexts); depth >= 0; depth-- {
+		for _, c := range p.pending {
+			if !c.waiting() && p.depthOf(c) == depth {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// depthOf returns the number of callbacks pending when the request was sent.
+func (p *parser) depthOf(c *call) int {
+	for depth := len(p.contexts); depth > 0; depth-- {
+		for _, child := range p.contexts[depth-1].Children {
+			if child == c {
+				return depth
+			}
+		}
+	}
+	return 0
+}
+
+// recordResult records the interfaces of objects created with some.
+func (p *parser) recordResult(c *call) {
+	create, ok := c.Request.Props.(*protocol.CreateProps)
+	if !ok || len(create.Interfaces) == 0 {
+		return
+	}
+	var ref protocol.ObjectRef
+	if json.Unmarshal(c.Result, &ref) == nil && ref.InstanceID != "" {
+		p.session.objects[ref.InstanceID] = create.Interfaces
+	}
+}
+
+// recordRefs records the interfaces of the objects callbacks target, as these
+// are announced by the kernel.
+func (p *parser) recordRefs(invoke *protocol.InvokeCallback, get *protocol.GetCallback, set *protocol.SetCallback) {
+	var ref protocol.ObjectRef
+	switch {
+	case invoke != nil:
+		ref = invoke.ObjRef
+	case get != nil:
+		ref = get.ObjRef
+	case set != nil:
+		ref = set.ObjRef
+	}
+	if len(ref.Interfaces) > 0 {
+		p.session.objects[ref.InstanceID] = ref.Interfaces
+	}
+}
+
+// decode decodes JSON data, with numbers decoded as json.Number so they are
+// rendered as they were sent.
+func decode(data []byte, into interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(into)
+}
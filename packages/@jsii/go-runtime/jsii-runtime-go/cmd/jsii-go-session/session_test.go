@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// trace is a session in which a go override (Add) is called back while
+// invoking a method, and reads a property while fulfilling it. Two property
+// reads are then pipelined.
+const trace = `2026-01-02T03:04:05Z < {"hello":"@jsii/runtime@1.2.3"}
+2026-01-02T03:04:05Z > {"api":"create","fqn":"Object","interfaces":["calc.IAdder"],"overrides":[{"method":"add","cookie":"Add"}]}
+2026-01-02T03:04:05.001Z < {"ok":{"$jsii.byref":"Object@10000"}}
+2026-01-02T03:04:05.001Z > {"api":"sinvoke","fqn":"calc.Runner","method":"run","args":[{"$jsii.byref":"Object@10000"},1]}
+2026-01-02T03:04:05.002Z < {"callback":{"cbid":"jsii::callback::1","cookie":"Add","invoke":{"objref":{"$jsii.byref":"Object@10000"},"method":"add","args":[1]}}}
+2026-01-02T03:04:05.003Z > {"api":"get","property":"offset","objref":{"$jsii.byref":"calc.Runner@10001"}}
+2026-01-02T03:04:05.004Z < {"ok":{"value":41}}
+2026-01-02T03:04:05.005Z > {"complete":{"cbid":"jsii::callback::1","result":42}}
+2026-01-02T03:04:05.007Z < {"ok":{"result":42}}
+2026-01-02T03:04:05.008Z > {"api":"get","property":"a","objref":{"$jsii.byref":"calc.Runner@10001"}}
+2026-01-02T03:04:05.008Z > {"api":"get","property":"b","objref":{"$jsii.byref":"calc.Runner@10001"}}
+2026-01-02T03:04:05.009Z < {"ok":{"value":"a"}}
+2026-01-02T03:04:05.010Z < {"error":"no such property: b","name":"@jsii/kernel.RuntimeError"}
+`
+
+func TestTree(t *testing.T) {
+	s, err := parseSession(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	printTree(&out, s, true, 0)
+	expected := `# @jsii/runtime@1.2.3
+create Object() => Object@10000<calc.IAdder>  (1ms)
+sinvoke calc.Runner.run(Object@10000<calc.IAdder>, 1) => 42  (6ms)
+  callback Object@10000<calc.IAdder>.add(1) [Add] => 42  (3ms)
+    get calc.Runner@10001.offset => 41  (1ms)
+get calc.Runner@10001.a => "a"  (1ms)
+get calc.Runner@10001.b !! no such property: b  (2ms)
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, out.String())
+	}
+}
+
+func TestSummary(t *testing.T) {
+	s, err := parseSession(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	printSummary(&out, s)
+	// The table is aligned with spaces, which are irrelevant here.
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	summary := strings.Join(lines, "\n")
+	for _, expected := range []string{
+		"runtime: @jsii/runtime@1.2.3\nduration: 10ms\n",
+		"requests count errors total mean max\ncreate 1 0 1ms 1ms 1ms\nget 3 1 4ms 1.333333ms 2ms\nsinvoke 1 0 6ms 6ms 6ms\nall 5 1 11ms 2.2ms 6ms\n",
+		"callbacks count errors total mean max\ninvoke 1 0 3ms 3ms 3ms\nall 1 0 3ms 3ms 3ms\n",
+		"objects created:\nObject: 1",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected %q in:\n%v", expected, out.String())
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a, err := parseSession(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same session, with different instance IDs and a different result.
+	changed := strings.NewReplacer("10000", "20000", `"value":41`, `"value":40`, "42", "41").Replace(trace)
+	b, err := parseSession(strings.NewReader(changed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if printDiff(&out, "a", a, "a", a, 1) {
+		t.Errorf("expected no differences, got:\n%v", out.String())
+	}
+	if !printDiff(&out, "a", a, "b", b, 1) {
+		t.Fatal("expected differences")
+	}
+	expected := `--- a
++++ b
+# first difference at call 2 of a, call 2 of b
+@@ -1,5 +1,5 @@
+ create Object() => Object#1<calc.IAdder>
+-sinvoke calc.Runner.run(Object#1<calc.IAdder>, 1) => 42
+-  callback Object#1<calc.IAdder>.add(1) [Add] => 42
+-    get calc.Runner#1.offset => 41
++sinvoke calc.Runner.run(Object#1<calc.IAdder>, 1) => 41
++  callback Object#1<calc.IAdder>.add(1) [Add] => 41
++    get calc.Runner#1.offset => 40
+ get calc.Runner#1.a => "a"
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, out.String())
+	}
+}
+
+func TestDiffReportsCallNumbers(t *testing.T) {
+	a, err := parseSession(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same session, with a call appended.
+	extra := `2026-01-02T03:04:05.011Z > {"api":"get","property":"c","objref":{"$jsii.byref":"calc.Runner@10001"}}
+2026-01-02T03:04:05.012Z < {"ok":{"value":"c"}}
+`
+	b, err := parseSession(strings.NewReader(trace + extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if !printDiff(&out, "a", a, "b", b, 0) {
+		t.Fatal("expected differences")
+	}
+	if expected := "# first difference at call 7 of a, call 7 of b\n"; !strings.Contains(out.String(), expected) {
+		t.Errorf("expected %q in:\n%v", expected, out.String())
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	long := strings.Repeat("abcdefghij", 2000)
+	for _, tc := range []struct {
+		a, b    string
+		changes int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abcabba", "cbabac", 5},
+		{long, long[:10000] + "x" + long[10001:], 2},
+	} {
+		a, b := strings.Split(tc.a, ""), strings.Split(tc.b, "")
+		var gotA, gotB []string
+		changes := 0
+		for _, e := range diffLines(a, b) {
+			if e.op != '+' {
+				gotA = append(gotA, e.line)
+			}
+			if e.op != '-' {
+				gotB = append(gotB, e.line)
+			}
+			if e.op != ' ' {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != tc.a || strings.Join(gotB, "") != tc.b {
+			t.Errorf("the edits of %q into %q do not reproduce them: %q, %q", tc.a, tc.b, gotA, gotB)
+		} else if changes != tc.changes {
+			t.Errorf("expected %d changes of %q into %q, got %d", tc.changes, tc.a, tc.b, changes)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"> {\"complete\":{\"cbid\":\"unknown\"}}\n",
+		"< {\"ok\":{}}\n",
+		"garbage\n",
+	} {
+		if _, err := parseSession(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package jsii
+
+import "github.com/aws/jsii-runtime-go/internal/kernel"
+
+// EnvironmentPolicy controls which environment variables of this program are
+// forwarded to the jsii kernel process, which allows running third-party jsii
+// libraries without exposing credentials or tokens they do not need. Patterns
+// use the path.Match syntax (e.g: "AWS_*").
+//
+// If neither Clean nor Allow are set, all variables are forwarded. Otherwise,
+// only the variables matching Allow are forwarded, in addition to the minimal
+// set needed by node and jsii (PATH, HOME, TMPDIR, JSII_*, ...) if Clean is
+// set. Variables matching Deny are never forwarded. Extra variables are always
+// set, and JSII_AGENT is always controlled by the runtime.
+//
+// The default policy is described by the JSII_ENV_POLICY ("clean" or
+// "inherit"), JSII_ENV_ALLOW and JSII_ENV_DENY (comma-separated patterns)
+// environment variables.
+type EnvironmentPolicy = kernel.EnvironmentPolicy
+
+// GetEnvironmentPolicy returns the policy applied to the environment of the
+// jsii kernel process. This panics if the environment variables describing the
+// default policy are invalid.
+func GetEnvironmentPolicy() EnvironmentPolicy {
+	policy, err := kernel.GetEnvironmentPolicy()
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+// SetEnvironmentPolicy changes the policy applied to the environment of the
+// jsii kernel process. It must be called before the first use of any jsii
+// library (or after Close) to have an effect, and returns an error if any of
+// the policy's patterns is malformed.
+func SetEnvironmentPolicy(policy EnvironmentPolicy) error {
+	return kernel.SetEnvironmentPolicy(policy)
+}
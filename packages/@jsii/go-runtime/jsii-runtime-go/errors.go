@@ -0,0 +1,19 @@
+package jsii
+
+import "github.com/aws/jsii-runtime-go/internal/kernel"
+
+// JavaScriptError is the error (panic) value raised when a call into the jsii
+// kernel fails, typically because the JavaScript code threw an exception. It
+// carries the JavaScript stack trace in addition to the go stack trace of the
+// failed call, both of which are rendered by the %+v formatting verb.
+//
+// If the exception was caused by an error or panic in go code fulfilling a
+// callback for the failed call (such as a method override), the corresponding
+// GoError is its Cause, and can be retrieved using errors.As.
+type JavaScriptError = kernel.JavaScriptError
+
+// GoError describes an error returned by, or a panic raised in, go code that
+// fulfills a callback from the jsii kernel (such as a method override). Such
+// errors are reported to the JavaScript code as exceptions, the message of
+// which includes the go type name and stack trace.
+type GoError = kernel.GoError
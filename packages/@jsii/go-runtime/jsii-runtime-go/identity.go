@@ -0,0 +1,108 @@
+package jsii
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+)
+
+// InstanceID returns the jsii kernel instance ID of the object the provided
+// value refers to (e.g: "jsii-calc.Calculator@10003"), and whether the value
+// refers to an object known to the kernel at all.
+func InstanceID(obj interface{}) (id string, found bool) {
+	ref, found := kernel.GetClient().FindObjectRef(reflect.ValueOf(obj))
+	return ref.InstanceID, found
+}
+
+// SameObject determines whether the two provided values refer to the same
+// object. Distinct go proxy values that refer to the same jsii kernel object
+// (for example, as a result of UnsafeCast) are considered the same, while
+// values that are not known to the kernel are compared by address.
+func SameObject(a, b interface{}) bool {
+	return kernel.GetClient().SameObject(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// ObjectDescription describes the jsii kernel object a go value refers to. It
+// is obtained using Describe, and is intended for use in logs and debugging
+// sessions, as it formats proxies in a meaningful way.
+type ObjectDescription struct {
+	// InstanceID is the jsii kernel instance ID of the object, or "" if the
+	// value does not refer to an object known to the kernel.
+	InstanceID string
+	// Interfaces lists the fully qualified names of the interfaces the object
+	// is known to implement, in addition to those implemented by its class.
+	Interfaces []string
+
+	value interface{}
+}
+
+// Describe returns a description of the jsii kernel object the provided value
+// refers to. The result implements fmt.Stringer and fmt.Formatter so that it
+// can be passed directly to functions such as fmt.Printf: the %v and %s verbs
+// render the instance ID (e.g: "jsii-calc.Calculator@10003"), while %+v also
+// lists the known interfaces. Values that do not refer to an object known to
+// the kernel are formatted as they would normally be.
+func Describe(obj interface{}) ObjectDescription {
+	desc := ObjectDescription{value: obj}
+	if ref, found := kernel.GetClient().FindObjectRef(reflect.ValueOf(obj)); found {
+		desc.InstanceID = ref.InstanceID
+		for _, iface := range ref.Interfaces {
+			desc.Interfaces = append(desc.Interfaces, string(iface))
+		}
+		sort.Strings(desc.Interfaces)
+	}
+	return desc
+}
+
+// String renders the instance ID of the described object.
+func (d ObjectDescription) String() string {
+	if d.InstanceID == "" {
+		return fmt.Sprint(d.value)
+	}
+	return d.InstanceID
+}
+
+// Format implements fmt.Formatter. The %+v verb includes the object's known
+// interfaces, if any.
+func (d ObjectDescription) Format(f fmt.State, verb rune) {
+	if d.InstanceID == "" {
+		// Not a kernel object, so we render the original value as-is.
+		fmt.Fprintf(f, formatString(f, verb), d.value)
+		return
+	}
+
+	switch verb {
+	case 'v', 's':
+		fmt.Fprint(f, d.InstanceID)
+		if f.Flag('+') && len(d.Interfaces) > 0 {
+			fmt.Fprintf(f, " (%v)", strings.Join(d.Interfaces, ", "))
+		}
+	case 'q':
+		fmt.Fprintf(f, "%q", d.InstanceID)
+	default:
+		fmt.Fprintf(f, "%%!%c(jsii.ObjectDescription=%v)", verb, d.InstanceID)
+	}
+}
+
+// formatString re-creates the formatting directive that resulted in a call to
+// fmt.Formatter.Format with the provided state and verb.
+func formatString(f fmt.State, verb rune) string {
+	var directive strings.Builder
+	directive.WriteRune('%')
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			directive.WriteRune(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		fmt.Fprint(&directive, width)
+	}
+	if precision, ok := f.Precision(); ok {
+		fmt.Fprintf(&directive, ".%d", precision)
+	}
+	directive.WriteRune(verb)
+	return directive.String()
+}
@@ -0,0 +1,53 @@
+package jsii
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectIdentity(t *testing.T) {
+	client := kernel.GetClient()
+
+	object := NewMockInterfaceA()
+	alias := NewMockInterfaceB()
+	other := NewMockInterfaceA()
+	client.RegisterInstance(reflect.ValueOf(object), api.ObjectRef{InstanceID: "mock.ClassA@30001"})
+	client.RegisterInstance(reflect.ValueOf(alias), api.ObjectRef{InstanceID: "mock.ClassA@30001"})
+	client.RegisterInstance(reflect.ValueOf(other), api.ObjectRef{InstanceID: "mock.ClassA@30002"})
+
+	id, found := InstanceID(alias)
+	assert.True(t, found)
+	assert.Equal(t, "mock.ClassA@30001", id)
+
+	assert.True(t, SameObject(object, alias))
+	assert.False(t, SameObject(object, other))
+
+	// Values unknown to the kernel are compared by address
+	native := NewMockInterfaceB()
+	assert.True(t, SameObject(native, native))
+	assert.False(t, SameObject(native, NewMockInterfaceB()))
+	assert.False(t, SameObject(native, object))
+	_, found = InstanceID(native)
+	assert.False(t, found)
+}
+
+func TestDescribe(t *testing.T) {
+	client := kernel.GetClient()
+
+	object := NewMockInterfaceA()
+	client.RegisterInstance(reflect.ValueOf(object), api.ObjectRef{InstanceID: "Object@30003", Interfaces: []api.FQN{"mock.IZeta", "mock.IAlpha"}})
+
+	assert.Equal(t, "Object@30003", fmt.Sprintf("%v", Describe(object)))
+	assert.Equal(t, "Object@30003", Describe(object).String())
+	assert.Equal(t, "Object@30003 (mock.IAlpha, mock.IZeta)", fmt.Sprintf("%+v", Describe(object)))
+	assert.Equal(t, `"Object@30003"`, fmt.Sprintf("%q", Describe(object)))
+
+	// Other values are formatted normally
+	assert.Equal(t, "  1337", fmt.Sprintf("%6v", Describe(1337)))
+	assert.Equal(t, "1337", Describe(1337).String())
+}
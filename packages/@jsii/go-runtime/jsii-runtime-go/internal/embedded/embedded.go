@@ -1,9 +1,13 @@
 package embedded
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"io/fs"
 	"os"
 	"path"
+	"sync"
 )
 
 // embeddedRootDir is the name of the root directory for the embeddedFS variable.
@@ -55,3 +59,36 @@ func extractRuntime(into string, from string) error {
 	}
 	return nil
 }
+
+var (
+	bundleHash     string
+	bundleHashOnce sync.Once
+)
+
+// BundleHash returns the hex-encoded SHA-256 digest of the embedded runtime
+// library, which identifies the exact bundle in use. It covers the path and
+// contents of every file, in lexical order.
+func BundleHash() string {
+	bundleHashOnce.Do(func() {
+		digest := sha256.New()
+		err := fs.WalkDir(embeddedFS, embeddedRootDir, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			data, err := embeddedFS.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			digest.Write([]byte(file))
+			digest.Write([]byte{0})
+			digest.Write(data)
+			return nil
+		})
+		if err != nil {
+			// The embedded file system is read-only, so this cannot happen.
+			panic(err)
+		}
+		bundleHash = hex.EncodeToString(digest.Sum(nil))
+	})
+	return bundleHash
+}
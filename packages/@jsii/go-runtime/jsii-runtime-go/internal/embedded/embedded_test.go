@@ -1,6 +1,7 @@
 package embedded
 
 import (
+	"encoding/hex"
 	"path"
 	"testing"
 )
@@ -11,5 +12,14 @@ func TestEntryPointExists(t *testing.T) {
 	} else if len(bytes) == 0 {
 		t.Error("entry point file is empty")
 	}
+}
 
+func TestBundleHash(t *testing.T) {
+	hash := BundleHash()
+	if digest, err := hex.DecodeString(hash); err != nil || len(digest) != 32 {
+		t.Errorf("expected a hex-encoded SHA-256 digest, got %q", hash)
+	}
+	if again := BundleHash(); again != hash {
+		t.Errorf("expected a stable hash, got %v then %v", hash, again)
+	}
 }
@@ -1,25 +1,21 @@
 package kernel
 
-import (
-	"github.com/aws/jsii-runtime-go/internal/api"
-)
-
-type BeginProps struct {
-	Method    *string       `json:"method"`
-	Arguments []interface{} `json:"args"`
-	ObjRef    api.ObjectRef `json:"objref"`
-}
+import "github.com/aws/jsii-runtime-go/protocol"
 
-type BeginResponse struct {
-	kernelResponse
-	PromiseID *string `json:"promise_id"`
-}
+type (
+	BeginProps    = protocol.BeginProps
+	BeginResponse = protocol.BeginResponse
+)
 
 func (c *Client) Begin(props BeginProps) (response BeginResponse, err error) {
-	type request struct {
-		kernelRequest
-		BeginProps
+	// Counted before the request is sent, so the kernel is not recycled while
+	// the promise is in progress (see SafePoint).
+	c.conversation.update(func() { c.conversation.promises++ })
+
+	// Async operations are not allowed while a callback is pending.
+	err = c.exchange(protocol.NewRequest("begin", props), &response, c.conversation.noCallbacks)
+	if err != nil {
+		c.conversation.update(func() { c.conversation.promises-- })
 	}
-	err = c.request(request{kernelRequest{"begin"}, props}, &response)
 	return
 }
@@ -3,101 +3,128 @@ package kernel
 import (
 	"fmt"
 	"reflect"
+	"sync"
 
-	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/protocol"
 )
 
-type callback struct {
-	CallbackID string          `json:"cbid"`
-	Cookie     string          `json:"cookie"`
-	Invoke     *invokeCallback `json:"invoke"`
-	Get        *getCallback    `json:"get"`
-	Set        *setCallback    `json:"set"`
-}
+// callback is a request from the kernel to execute go code. It is defined on
+// top of protocol.Callback so it can have the methods that fulfill it.
+type callback protocol.Callback
+
+// callbackResult describes the outcome of a callback.
+type callbackResult = protocol.CallbackResult
+
+// handle executes the callback, and returns its outcome, which must be sent to
+// the kernel to signal its completion. If the callback could not be fulfilled
+// (e.g: the go override returned an error, or panicked), the outcome reports
+// the failure to the kernel, and the corresponding GoError is returned.
+func (c *callback) handle(client *Client) (result callbackResult, goErr *GoError) {
+	result.CallbackID = c.CallbackID
+
+	// Whatever happens, the kernel must be notified of the callback's completion,
+	// or it would wait forever.
+	defer func() {
+		if r := recover(); r != nil {
+			goErr = newGoError(r, true)
+		}
+		if goErr != nil {
+			goErr.callbackID = c.CallbackID
+			result.Result = nil
+			result.Error = goErr.message()
+		}
+	}()
 
-func (c *callback) handle(result kernelResponder) error {
 	var (
 		retval reflect.Value
 		err    error
 	)
 	if c.Invoke != nil {
-		retval, err = c.Invoke.handle(c.Cookie)
+		retval, err = (*invokeCallback)(c.Invoke).handle(client, c.Cookie)
 	} else if c.Get != nil {
-		retval, err = c.Get.handle(c.Cookie)
+		retval, err = (*getCallback)(c.Get).handle(client, c.Cookie)
 	} else if c.Set != nil {
-		retval, err = c.Set.handle(c.Cookie)
+		retval, err = (*setCallback)(c.Set).handle(client, c.Cookie)
 	} else {
-		return fmt.Errorf("invalid callback object: %v", c)
+		err = fmt.Errorf("invalid callback object: %v", c)
 	}
 
 	if err != nil {
-		return err
+		return result, newGoError(err, false)
 	}
 
-	type callbackResult struct {
-		CallbackID string      `json:"cbid"`
-		Result     interface{} `json:"result,omitempty"`
-		Error      string      `json:"err,omitempty"`
-	}
-	type completeRequest struct {
-		kernelRequester
-		callbackResult `json:"complete"`
-	}
-
-	client := GetClient()
-	request := completeRequest{}
-	request.CallbackID = c.CallbackID
-	request.Result = client.CastPtrToRef(retval)
-	if err != nil {
-		request.Error = err.Error()
-	}
-	return client.request(request, result)
-}
-
-type invokeCallback struct {
-	Method    string        `json:"method"`
-	Arguments []interface{} `json:"args"`
-	ObjRef    api.ObjectRef `json:"objref"`
+	result.Result = client.CastPtrToRef(retval)
+	return
 }
 
-func (i *invokeCallback) handle(cookie string) (retval reflect.Value, err error) {
-	client := GetClient()
+type invokeCallback protocol.InvokeCallback
 
+func (i *invokeCallback) handle(client *Client, cookie string) (retval reflect.Value, err error) {
 	receiver := reflect.ValueOf(client.GetObject(i.ObjRef))
-	method := receiver.MethodByName(cookie)
+	method := callbackMethod(receiver, cookie)
 
 	return client.invoke(method, i.Arguments)
 }
 
-type getCallback struct {
-	Property string        `json:"property"`
-	ObjRef   api.ObjectRef `json:"objref"`
-}
-
-func (g *getCallback) handle(cookie string) (retval reflect.Value, err error) {
-	client := GetClient()
+type getCallback protocol.GetCallback
 
+func (g *getCallback) handle(client *Client, cookie string) (retval reflect.Value, err error) {
 	receiver := reflect.ValueOf(client.GetObject(g.ObjRef))
-	method := receiver.MethodByName(cookie)
+	method := callbackMethod(receiver, cookie)
 
 	return client.invoke(method, nil)
 }
 
-type setCallback struct {
-	Property string        `json:"property"`
-	Value    interface{}   `json:"value"`
-	ObjRef   api.ObjectRef `json:"objref"`
-}
-
-func (s *setCallback) handle(cookie string) (retval reflect.Value, err error) {
-	client := GetClient()
+type setCallback protocol.SetCallback
 
+func (s *setCallback) handle(client *Client, cookie string) (retval reflect.Value, err error) {
 	receiver := reflect.ValueOf(client.GetObject(s.ObjRef))
-	method := receiver.MethodByName(fmt.Sprintf("Set%v", cookie))
+	method := callbackMethod(receiver, fmt.Sprintf("Set%v", cookie))
 
 	return client.invoke(method, []interface{}{s.Value})
 }
 
+// callbackMethod returns the method with the provided name on the receiver of
+// a callback. Objects created by Implement resolve it from their functions.
+func callbackMethod(receiver reflect.Value, name string) reflect.Value {
+	if obj, ok := receiver.Interface().(*closureObject); ok {
+		return obj.method(name)
+	}
+	return methodByName(receiver, name)
+}
+
+// methodKey identifies a method by name on a given receiver type.
+type methodKey struct {
+	receiver reflect.Type
+	name     string
+}
+
+// methodIndexCache associates a methodKey with the index of the method in the
+// receiver type's method set, or -1 if there is no such method. Method sets
+// never change at runtime, so entries never need to be invalidated.
+var methodIndexCache sync.Map // map[methodKey]int
+
+// methodByName is equivalent to receiver.MethodByName(name), except the
+// method's lookup is cached for each receiver type.
+func methodByName(receiver reflect.Value, name string) reflect.Value {
+	key := methodKey{receiver.Type(), name}
+
+	index, found := methodIndexCache.Load(key)
+	if !found {
+		if method, ok := key.receiver.MethodByName(name); ok {
+			index = method.Index
+		} else {
+			index = -1
+		}
+		methodIndexCache.Store(key, index)
+	}
+
+	if index.(int) < 0 {
+		return reflect.Value{}
+	}
+	return receiver.Method(index.(int))
+}
+
 func (c *Client) invoke(method reflect.Value, args []interface{}) (retval reflect.Value, err error) {
 	if !method.IsValid() {
 		err = fmt.Errorf("invalid method")
@@ -135,10 +162,9 @@ func (c *Client) invoke(method reflect.Value, args []interface{}) (retval reflec
 	defer func() {
 		if r := recover(); r != nil {
 			if err == nil {
-				var ok bool
-				if err, ok = r.(error); !ok {
-					err = fmt.Errorf("%v", r)
-				}
+				// The stack trace is captured here, as it still includes the
+				// frames of the go code that panicked.
+				err = newGoError(r, true)
 			} else {
 				// This is not expected - so we panic!
 				panic(r)
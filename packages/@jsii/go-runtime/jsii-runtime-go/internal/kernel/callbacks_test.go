@@ -0,0 +1,35 @@
+package kernel
+
+import (
+	"reflect"
+	"testing"
+)
+
+type callbackReceiver struct {
+	value string
+}
+
+func (r *callbackReceiver) Value() string {
+	return r.value
+}
+
+func (r *callbackReceiver) SetValue(value string) {
+	r.value = value
+}
+
+func TestMethodByName(t *testing.T) {
+	for _, receiver := range []*callbackReceiver{{"first"}, {"second"}} {
+		method := methodByName(reflect.ValueOf(receiver), "Value")
+		if !method.IsValid() {
+			t.Fatal("expected to find the Value method")
+		}
+		// The method must be bound to the correct receiver, despite caching.
+		if result := method.Call(nil)[0].String(); result != receiver.value {
+			t.Errorf("expected %v, got %v", receiver.value, result)
+		}
+
+		if method := methodByName(reflect.ValueOf(receiver), "Missing"); method.IsValid() {
+			t.Errorf("expected no method, got %v", method)
+		}
+	}
+}
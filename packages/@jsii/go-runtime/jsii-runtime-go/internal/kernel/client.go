@@ -1,21 +1,25 @@
 package kernel
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"reflect"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/aws/jsii-runtime-go/internal/api"
 	"github.com/aws/jsii-runtime-go/internal/kernel/process"
 	"github.com/aws/jsii-runtime-go/internal/objectstore"
 	"github.com/aws/jsii-runtime-go/internal/typeregistry"
+	"github.com/aws/jsii-runtime-go/protocol"
 )
 
 var (
 	clientInstance      *Client
 	clientInstanceMutex sync.Mutex
-	clientOnce          sync.Once
 	types               *typeregistry.TypeRegistry = typeregistry.New()
 )
 
@@ -24,32 +28,72 @@ var (
 // to call methods and access properties on objects passed by the runtime
 // process by reference.
 type Client struct {
-	process *process.Process
+	// The process is replaced when it is recycled (see RecyclePolicy), which
+	// happens while the conversation is acquired. Guards closed, and accesses
+	// to process that happen outside of the conversation.
+	process     *process.Process
+	processLock sync.Mutex
+	closed      bool
+
 	objects *objectstore.ObjectStore
 
+	// Serializes exchanges with the kernel process, see conversation.
+	conversation conversation
+	// The goroutines fulfilling callbacks that timed out, which may no longer
+	// make requests.
+	abandoned abandonedCallbacks
+
+	// Speaks the protocol with the kernel process, once the features it supports
+	// have been negotiated. It is only used while the conversation is acquired.
+	wire *protocol.Client
+
 	// Supports the idempotency of the Load method.
 	loaded map[LoadProps]LoadResponse
+	// The assemblies loaded so far, in order, to be loaded again when the
+	// process is recycled.
+	assemblies []loadedAssembly
+	// The type hierarchy declared by the first hierarchyLoaded assemblies, see
+	// typeHierarchy.
+	hierarchy       typeHierarchy
+	hierarchyLoaded int
+
+	// The number of times the process was recycled.
+	recycles int
+
+	// The writer messages are traced to, and the file the session is recorded
+	// to, if JSII_TRACE_FILE is set.
+	trace     io.Writer
+	traceFile io.Closer
 }
 
+// JSII_TRACE_FILE is the name of the environment variable that makes clients
+// record all messages exchanged with the @jsii/kernel process to the file at
+// the specified path, with timestamps (see protocol.TimestampedTrace).
+const JSII_TRACE_FILE = "JSII_TRACE_FILE"
+
 // GetClient returns a singleton Client instance, initializing one the first
 // time it is called.
 func GetClient() *Client {
-	clientOnce.Do(func() {
-		// Locking early to be safe with a concurrent Close execution
-		clientInstanceMutex.Lock()
-		defer clientInstanceMutex.Unlock()
+	// Locking to be safe with a concurrent CloseClient execution
+	clientInstanceMutex.Lock()
+	defer clientInstanceMutex.Unlock()
 
+	if clientInstance == nil {
 		client, err := newClient()
 		if err != nil {
 			panic(err)
 		}
 
 		clientInstance = client
-	})
+	}
 
 	return clientInstance
 }
 
+// CloseStage identifies the step of closing the @jsii/kernel process that
+// caused it to exit (see process.CloseStage).
+type CloseStage = process.CloseStage
+
 // CloseClient finalizes the runtime process, signalling the end of the
 // execution to the jsii kernel process, and waiting for graceful termination.
 //
@@ -57,23 +101,48 @@ func GetClient() *Client {
 // process will be initialized, and CloseClient should be called again to
 // correctly finalize that, too.
 func CloseClient() {
-	// Locking early to be safe with a concurrent getClient execution
+	CloseClientContext(context.Background())
+}
+
+// CloseClientWithTimeout is like CloseClient, but gives the jsii kernel process
+// up to the provided timeout to exit gracefully, after which it is sent SIGTERM,
+// then killed. It returns the stage that caused the process to exit, and an
+// error if it did not exit at all.
+func CloseClientWithTimeout(timeout time.Duration) (CloseStage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return CloseClientContext(ctx)
+}
+
+// CloseClientContext is like CloseClientWithTimeout, but escalates once the
+// provided context is done. It is safe to call concurrently with in-flight
+// requests, which fail once the process has been closed.
+func CloseClientContext(ctx context.Context) (CloseStage, error) {
+	// Detaching the client while locked, so that it is closed only once, and
+	// a new one can be initialized in the meantime.
 	clientInstanceMutex.Lock()
-	defer clientInstanceMutex.Unlock()
+	client := clientInstance
+	clientInstance = nil
+	clientInstanceMutex.Unlock()
 
-	// Reset the "once" so a new Client would get initialized next time around
-	clientOnce = sync.Once{}
+	if client == nil {
+		return process.CloseStageNone, nil
+	}
+	return client.closeContext(ctx)
+}
 
-	if clientInstance != nil {
-		// Close the Client & reset it
-		clientInstance.close()
-		clientInstance = nil
+// newProcess prepares a new @jsii/kernel process.
+func newProcess() (*process.Process, error) {
+	policy, err := GetEnvironmentPolicy()
+	if err != nil {
+		return nil, err
 	}
+	return process.NewProcess(fmt.Sprintf("^%v", version), policy)
 }
 
 // newClient initializes a client, making it ready for business.
 func newClient() (*Client, error) {
-	if process, err := process.NewProcess(fmt.Sprintf("^%v", version)); err != nil {
+	if process, err := newProcess(); err != nil {
 		return nil, err
 	} else {
 		result := &Client{
@@ -82,6 +151,16 @@ func newClient() (*Client, error) {
 			loaded:  make(map[LoadProps]LoadResponse),
 		}
 
+		if path := os.Getenv(JSII_TRACE_FILE); path != "" {
+			file, err := os.Create(path)
+			if err != nil {
+				process.Close()
+				return nil, err
+			}
+			result.traceFile = file
+			result.SetTrace(protocol.TimestampedTrace(file))
+		}
+
 		// Register a finalizer to call Close()
 		runtime.SetFinalizer(result, func(c *Client) {
 			c.close()
@@ -91,6 +170,17 @@ func newClient() (*Client, error) {
 	}
 }
 
+// SetTrace makes the client write a copy of all messages exchanged with the
+// @jsii/kernel process to out, one per line, prefixed with "> " for requests
+// and "< " for responses. Passing nil stops tracing.
+func (c *Client) SetTrace(out io.Writer) {
+	c.processLock.Lock()
+	defer c.processLock.Unlock()
+
+	c.trace = out
+	c.process.SetTrace(out)
+}
+
 func (c *Client) Types() *typeregistry.TypeRegistry {
 	return types
 }
@@ -99,8 +189,57 @@ func (c *Client) RegisterInstance(instance reflect.Value, objectRef api.ObjectRe
 	return c.objects.Register(instance, objectRef)
 }
 
-func (c *Client) request(req kernelRequester, res kernelResponder) error {
-	return c.process.Request(req, res)
+func (c *Client) request(req protocol.Request, res protocol.Result) error {
+	return c.exchange(req, res, nil)
+}
+
+// exchange sends the provided request and processes its response, once the
+// conversation with the kernel is available and the ready condition holds (if
+// provided).
+func (c *Client) exchange(req protocol.Request, res protocol.Result, ready func() bool) error {
+	if err := c.abandoned.check(); err != nil {
+		return err
+	}
+
+	c.conversation.acquire(ready)
+	defer c.conversation.release()
+
+	return c.send(req, res)
+}
+
+// send validates and sends the provided request (see prepare) and processes
+// its response. The caller must have acquired the conversation with the kernel.
+func (c *Client) send(req protocol.Request, res protocol.Result) error {
+	if err := c.prepare(req); err != nil {
+		return err
+	}
+
+	env := newResponse(res)
+	if err := c.process.Request(req, &env); err != nil {
+		return err
+	}
+	return c.handleResponse(&env, res)
+}
+
+// prepare verifies that the provided requests can be sent to the kernel (see
+// protocol.Client.Prepare), starting it if that has not happened yet. Requests
+// are validated first, so that invalid ones do not start the kernel. The caller
+// must have acquired the conversation with the kernel.
+func (c *Client) prepare(reqs ...protocol.Request) error {
+	for _, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := c.negotiate(); err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		if err := c.wire.Prepare(req); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *Client) FindObjectRef(obj reflect.Value) (ref api.ObjectRef, found bool) {
@@ -127,6 +266,26 @@ func (c *Client) FindObjectRef(obj reflect.Value) (ref api.ObjectRef, found bool
 	}
 }
 
+// SameObject determines whether the two provided values refer to the same
+// object instance. Objects known to the kernel are compared by instance ID (so
+// distinct proxy values for the same object are considered the same), and
+// others by address.
+func (c *Client) SameObject(a, b reflect.Value) bool {
+	refA, foundA := c.FindObjectRef(a)
+	refB, foundB := c.FindObjectRef(b)
+	if foundA || foundB {
+		return foundA && foundB && refA.InstanceID == refB.InstanceID
+	}
+
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	return a.IsValid() && b.IsValid() && a.Kind() == reflect.Ptr && b.Kind() == reflect.Ptr && !a.IsNil() && a.Pointer() == b.Pointer()
+}
+
 func (c *Client) GetObject(objref api.ObjectRef) interface{} {
 	if obj, ok := c.objects.GetObject(objref.InstanceID); ok {
 		return obj.Interface()
@@ -135,8 +294,23 @@ func (c *Client) GetObject(objref api.ObjectRef) interface{} {
 }
 
 func (c *Client) close() {
-	c.process.Close()
+	c.closeContext(context.Background())
+}
+
+func (c *Client) closeContext(ctx context.Context) (CloseStage, error) {
+	c.processLock.Lock()
+	c.closed = true
+	process := c.process
+	c.processLock.Unlock()
+
+	stage, err := process.CloseContext(ctx)
+	if c.traceFile != nil {
+		c.traceFile.Close()
+		c.traceFile = nil
+	}
 
 	// We no longer need a finalizer to run
 	runtime.SetFinalizer(c, nil)
+
+	return stage, err
 }
@@ -1,9 +1,12 @@
 package kernel
 
 import (
+	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/aws/jsii-runtime-go/internal/api"
 	"github.com/aws/jsii-runtime-go/internal/typeregistry"
 )
 
@@ -60,3 +63,21 @@ func TestClient(t *testing.T) {
 		}
 	})
 }
+
+func TestClientValidatesRequests(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		var trace bytes.Buffer
+		client.SetTrace(&trace)
+		defer client.SetTrace(nil)
+
+		if _, err := client.Invoke(InvokeProps{Method: "echo"}); err == nil || !strings.Contains(err.Error(), "invalid invoke request: objref must reference an object") {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+		if _, err := client.GetAll(api.ObjectRef{InstanceID: "test.Level@1"}, []string{"field0", ""}); err == nil || !strings.Contains(err.Error(), "invalid get request") {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+		if trace.Len() != 0 {
+			t.Errorf("expected invalid requests not to be sent, got %v", trace.String())
+		}
+	})
+}
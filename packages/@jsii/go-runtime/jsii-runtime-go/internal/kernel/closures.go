@@ -0,0 +1,172 @@
+package kernel
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+// closureObject is the go side of a kernel object implementing a jsii
+// interface using go functions, rather than the methods of a go type. It is
+// the target of callbacks for that object.
+type closureObject struct {
+	mutex sync.Mutex
+	// methods associates the go name of each implemented member (and of the
+	// setter of implemented properties) with the function implementing it.
+	methods map[string]reflect.Value
+}
+
+// method returns the function implementing the member with the provided go
+// name, or an invalid value if there is none.
+func (o *closureObject) method(name string) reflect.Value {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.methods[name]
+}
+
+// Implement creates a kernel object implementing the jsii interface designated
+// by fqn, with members backed by the provided go functions. Members are keyed
+// by their jsii (e.g: "sayHello") or go (e.g: "SayHello") name. Methods must be
+// implemented by functions with the same signature as the corresponding method
+// of the go interface, while properties may be implemented by a getter function
+// or a value (in which case the property can be set by JavaScript code).
+// Members that are not implemented fail when they are called.
+//
+// Returns a proxy value of the go interface type, which can be passed to any
+// API accepting the interface.
+func (c *Client) Implement(fqn api.FQN, implementations map[string]interface{}) (interface{}, error) {
+	iface, found := c.Types().FindType(fqn)
+	if !found {
+		return nil, fmt.Errorf("%v is not a registered jsii type", fqn)
+	}
+	if _, isInterface := c.Types().InterfaceFQN(iface); !isInterface {
+		return nil, fmt.Errorf("%v is not a jsii interface", fqn)
+	}
+
+	members := make(map[string]api.Override)
+	for _, member := range c.Types().Members(fqn) {
+		members[member.GoName()] = member
+		switch m := member.(type) {
+		case api.MethodOverride:
+			members[m.JsiiMethod] = member
+		case api.PropertyOverride:
+			members[m.JsiiProperty] = member
+		}
+	}
+
+	obj := &closureObject{methods: make(map[string]reflect.Value)}
+	var (
+		overrides []api.Override
+		problems  []string
+	)
+	names := make([]string, 0, len(implementations))
+	for name := range implementations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		member, found := members[name]
+		if !found {
+			problems = append(problems, fmt.Sprintf("%v is not a member of %v", name, fqn))
+			continue
+		}
+		if _, duplicate := obj.methods[member.GoName()]; duplicate {
+			problems = append(problems, fmt.Sprintf("%v is implemented more than once", member.GoName()))
+			continue
+		}
+
+		impl := reflect.ValueOf(implementations[name])
+		method, found := iface.MethodByName(member.GoName())
+		if !found {
+			problems = append(problems, fmt.Sprintf("%v has no method named %v", iface, member.GoName()))
+			continue
+		}
+		if api.IsMethodOverride(member) {
+			if !impl.IsValid() || impl.Type() != method.Type {
+				problems = append(problems, fmt.Sprintf("%v must be implemented by a %v, not %v", name, method.Type, typeOf(impl)))
+				continue
+			}
+			obj.methods[member.GoName()] = impl
+		} else {
+			propType := method.Type.Out(0)
+			switch {
+			case impl.IsValid() && impl.Type() == method.Type:
+				obj.methods[member.GoName()] = impl
+			case !impl.IsValid() || impl.Type().AssignableTo(propType):
+				obj.setProperty(member.GoName(), propType, impl)
+			default:
+				problems = append(problems, fmt.Sprintf("%v must be implemented by a %v or a %v value, not %v", name, method.Type, propType, typeOf(impl)))
+				continue
+			}
+		}
+		overrides = append(overrides, member)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid implementation of %v:\n\t- %v", fqn, strings.Join(problems, "\n\t- "))
+	}
+
+	res, err := c.Create(CreateProps{
+		FQN:        objectFQN,
+		Interfaces: []api.FQN{fqn},
+		Overrides:  overrides,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ref := api.ObjectRef{InstanceID: res.InstanceID, Interfaces: []api.FQN{fqn}}
+
+	// The closure object is registered first, so that it receives callbacks,
+	// while go code uses the proxy (which calls through the kernel).
+	if err := c.objects.Register(reflect.ValueOf(obj), ref); err != nil {
+		return nil, err
+	}
+	proxy := reflect.New(iface).Elem()
+	if err := c.Types().InitJsiiProxy(proxy, iface); err != nil {
+		return nil, err
+	}
+	if err := c.RegisterInstance(proxy, ref); err != nil {
+		return nil, err
+	}
+	return proxy.Interface(), nil
+}
+
+// setProperty implements the property with the provided go name using the
+// given value, which can then be changed using the property's setter.
+func (o *closureObject) setProperty(name string, typ reflect.Type, value reflect.Value) {
+	if !value.IsValid() {
+		value = reflect.Zero(typ)
+	} else {
+		value = value.Convert(typ)
+	}
+
+	getterType := reflect.FuncOf(nil, []reflect.Type{typ}, false)
+	setterType := reflect.FuncOf([]reflect.Type{typ}, nil, false)
+
+	getter := reflect.MakeFunc(getterType, func([]reflect.Value) []reflect.Value {
+		o.mutex.Lock()
+		defer o.mutex.Unlock()
+		return []reflect.Value{value}
+	})
+	setter := reflect.MakeFunc(setterType, func(args []reflect.Value) []reflect.Value {
+		o.mutex.Lock()
+		defer o.mutex.Unlock()
+		value = args[0]
+		return nil
+	})
+
+	o.methods[name] = getter
+	o.methods[fmt.Sprintf("Set%v", name)] = setter
+}
+
+// typeOf returns the type of the provided value, or "nil".
+func typeOf(value reflect.Value) interface{} {
+	if !value.IsValid() {
+		return "nil"
+	}
+	return value.Type()
+}
@@ -0,0 +1,105 @@
+package kernel
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+type IGreeter interface {
+	Greet(name *string) *string
+	Volume() *float64
+	SetVolume(v *float64)
+}
+
+type jsiiProxy_IGreeter struct{}
+
+func (g *jsiiProxy_IGreeter) Greet(name *string) *string { panic("not implemented") }
+func (g *jsiiProxy_IGreeter) Volume() *float64           { panic("not implemented") }
+func (g *jsiiProxy_IGreeter) SetVolume(v *float64)       { panic("not implemented") }
+
+func registerGreeter(t *testing.T, client *Client) {
+	members := []api.Override{
+		api.MethodOverride{JsiiMethod: "greet", GoMethod: "Greet"},
+		api.PropertyOverride{JsiiProperty: "volume", GoGetter: "Volume"},
+	}
+	if err := client.Types().RegisterInterface("test.IGreeter", reflect.TypeOf((*IGreeter)(nil)).Elem(), members, func() interface{} { return &jsiiProxy_IGreeter{} }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImplement(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		registerGreeter(t, client)
+
+		volume := 11.0
+		greeter, err := client.Implement("test.IGreeter", map[string]interface{}{
+			"greet": func(name *string) *string {
+				greeting := "Hello, " + *name
+				return &greeting
+			},
+			"Volume": &volume,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := greeter.(IGreeter); !ok {
+			t.Fatalf("expected an IGreeter, got %T", greeter)
+		}
+		ref, found := client.FindObjectRef(reflect.ValueOf(greeter))
+		if !found {
+			t.Fatal("expected the proxy to be registered")
+		}
+
+		// Calls from JavaScript are fulfilled by the closures
+		if res, err := client.Invoke(InvokeProps{ObjRef: ref, Method: "greet", Arguments: []interface{}{"World"}}); err != nil {
+			t.Fatal(err)
+		} else if res.Result != "Hello, World" {
+			t.Errorf("expected Hello, World, got %#v", res.Result)
+		}
+		if res, err := client.Get(GetProps{ObjRef: ref, Property: "volume"}); err != nil {
+			t.Fatal(err)
+		} else if res.Value != json.Number("11") {
+			t.Errorf("expected 11, got %#v", res.Value)
+		}
+		if _, err := client.Set(SetProps{ObjRef: ref, Property: "volume", Value: 42.0}); err != nil {
+			t.Fatal(err)
+		}
+		if res, err := client.Get(GetProps{ObjRef: ref, Property: "volume"}); err != nil {
+			t.Fatal(err)
+		} else if res.Value != json.Number("42") {
+			t.Errorf("expected 42, got %#v", res.Value)
+		}
+	})
+}
+
+func TestImplementReportsAllProblems(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		registerGreeter(t, client)
+
+		_, err := client.Implement("test.IGreeter", map[string]interface{}{
+			"greet":   func(name string) string { return name },
+			"volume":  "loud",
+			"unknown": func() {},
+		})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		for _, expected := range []string{
+			"greet must be implemented by a func(*string) *string, not func(string) string",
+			"volume must be implemented by a func() *float64 or a *float64 value, not string",
+			"unknown is not a member of test.IGreeter",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected %q in error: %v", expected, err)
+			}
+		}
+
+		if _, err := client.Implement("test.Level", nil); err == nil {
+			t.Error("expected an error for a type that is not an interface")
+		}
+	})
+}
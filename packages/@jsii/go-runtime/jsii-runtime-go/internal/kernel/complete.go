@@ -1,21 +1,15 @@
 package kernel
 
-type CompleteProps struct {
-	CallbackID *string     `json:"cbid"`
-	Error      *string     `json:"err"`
-	Result     interface{} `json:"result"`
-}
+import "github.com/aws/jsii-runtime-go/protocol"
 
-type CompleteResponse struct {
-	kernelResponse
-	CallbackID *string `json:"cbid"`
-}
+type (
+	CompleteProps    = protocol.CompleteProps
+	CompleteResponse = protocol.CompleteResponse
+)
 
 func (c *Client) Complete(props CompleteProps) (response CompleteResponse, err error) {
-	type request struct {
-		kernelRequest
-		CompleteProps
-	}
-	err = c.request(request{kernelRequest{"complete"}, props}, &response)
+	// Only asynchronous callbacks are completed this way, which is not allowed
+	// while a (synchronous) callback is pending.
+	err = c.exchange(protocol.NewRequest("complete", props), &response, c.conversation.noCallbacks)
 	return
 }
@@ -0,0 +1,126 @@
+package kernel
+
+import "sync"
+
+// conversation serializes the exchanges of several goroutines with the kernel
+// process. An exchange (sending a request and reading its response) requires
+// exclusive use of the process, but this is released while go code fulfills a
+// callback, so that other goroutines (e.g: those running async overrides) can
+// proceed in the meantime. This is possible because the kernel keeps processing
+// requests while it waits for a callback to complete, however:
+//   - callbacks must be completed from the innermost outwards, as the kernel
+//     waits for the completion of the last callback it sent;
+//   - asynchronous operations (begin, end, and the completion of asynchronous
+//     callbacks) are forbidden while any callback is pending.
+//
+// The zero value is ready to use.
+type conversation struct {
+	mutex sync.Mutex
+	cond  *sync.Cond
+
+	// busy is true while an exchange is in progress.
+	busy bool
+	// callbacks is the stack of callbacks sent by the kernel that are waiting
+	// to be completed, innermost last.
+	callbacks []*callback
+	// async is the number of asynchronous callbacks currently being fulfilled.
+	async int
+	// promises is the number of asynchronous invocations that were begun, and
+	// have not ended yet.
+	promises int
+}
+
+// acquire waits until no exchange is in progress, and ready returns true (if
+// provided), then marks an exchange as in progress. ready is called with the
+// conversation's mutex held.
+func (v *conversation) acquire(ready func() bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.cond == nil {
+		v.cond = sync.NewCond(&v.mutex)
+	}
+	for v.busy || (ready != nil && !ready()) {
+		v.cond.Wait()
+	}
+	v.busy = true
+}
+
+// release marks the current exchange as over.
+func (v *conversation) release() {
+	v.update(func() { v.busy = false })
+}
+
+// update applies the provided change with the conversation's mutex held, and
+// wakes up goroutines waiting to acquire it.
+func (v *conversation) update(change func()) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	change()
+	if v.cond != nil {
+		v.cond.Broadcast()
+	}
+}
+
+// asyncCount returns the number of asynchronous callbacks currently being
+// fulfilled.
+func (v *conversation) asyncCount() int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.async
+}
+
+// noCallbacks is a readiness condition for asynchronous operations.
+func (v *conversation) noCallbacks() bool {
+	return len(v.callbacks) == 0
+}
+
+// idle is a readiness condition for operations that block the kernel until
+// all asynchronous work is done (i.e: end).
+func (v *conversation) idle() bool {
+	return len(v.callbacks) == 0 && v.async == 0
+}
+
+// quiescent returns true if no callback is pending or being fulfilled, and no
+// asynchronous invocation is in progress, so the kernel holds no state the go
+// side depends on, besides object references.
+func (v *conversation) quiescent() bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.idle() && v.promises == 0
+}
+
+// innermost returns a readiness condition for the completion of the callback
+// with the provided ID.
+func (v *conversation) innermost(cbid string) func() bool {
+	return func() bool {
+		return len(v.callbacks) > 0 && v.callbacks[len(v.callbacks)-1].CallbackID == cbid
+	}
+}
+
+// beginCallback records the provided callback as pending, and releases the
+// current exchange so other goroutines can proceed while it is fulfilled. It
+// returns the chain of pending callbacks, outermost first, and ending with the
+// provided one.
+func (v *conversation) beginCallback(cb *callback) (chain []CallbackFrame) {
+	v.update(func() {
+		v.callbacks = append(v.callbacks, cb)
+		v.busy = false
+
+		chain = make([]CallbackFrame, len(v.callbacks))
+		for i, pending := range v.callbacks {
+			chain[i] = pending.frame()
+		}
+	})
+	return
+}
+
+// endCallback waits until the callback with the provided ID is the innermost
+// pending one, and acquires the conversation to send its completion.
+func (v *conversation) endCallback(cbid string) {
+	v.acquire(v.innermost(cbid))
+	v.update(func() { v.callbacks = v.callbacks[:len(v.callbacks)-1] })
+}
@@ -1,6 +1,7 @@
 package kernel
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
@@ -9,7 +10,8 @@ import (
 )
 
 var (
-	anyType = reflect.TypeOf((*interface{})(nil)).Elem()
+	anyType  = reflect.TypeOf((*interface{})(nil)).Elem()
+	jsonType = reflect.TypeOf(api.JSON(nil))
 )
 
 // CastAndSetToPtr accepts a pointer to any type and attempts to cast the value
@@ -47,19 +49,32 @@ func (c *Client) castAndSetToPtr(ptr reflect.Value, data reflect.Value) {
 		data = reflect.ValueOf(data.Interface())
 	}
 
+	if ptr.Type() == jsonType {
+		// JSON values are passed through verbatim.
+		json, err := castValToJSON(data)
+		if err != nil {
+			panic(err)
+		}
+		ptr.Set(reflect.ValueOf(json))
+		return
+	}
+
 	if ref, isRef := castValToRef(data); isRef {
 		// If return data is a jsii struct passed by reference, de-reference it all.
 		if fields, _, isStruct := c.Types().StructFields(ptr.Type()); isStruct {
-			for _, field := range fields {
-				got, err := c.Get(GetProps{
-					Property: field.Tag.Get("json"),
-					ObjRef:   ref,
-				})
-				if err != nil {
-					panic(err)
-				}
+			// All fields are fetched at once, so this costs a single round-trip
+			// per struct (nested structs each require their own).
+			properties := make([]string, len(fields))
+			for i, field := range fields {
+				properties[i] = field.Tag.Get("json")
+			}
+			got, err := c.GetAll(ref, properties)
+			if err != nil {
+				panic(err)
+			}
+			for i, field := range fields {
 				fieldVal := ptr.FieldByIndex(field.Index)
-				c.castAndSetToPtr(fieldVal, reflect.ValueOf(got.Value))
+				c.castAndSetToPtr(fieldVal, reflect.ValueOf(got[i].Value))
 			}
 			return
 		}
@@ -127,6 +142,23 @@ func (c *Client) castAndSetToPtr(ptr reflect.Value, data reflect.Value) {
 		return
 	}
 
+	// numbers
+	if num, isNum, err := castValToNumber(data, ptr.Type()); isNum {
+		if err != nil {
+			panic(err)
+		}
+		ptr.Set(num)
+		return
+	}
+
+	// other JSON objects, which may contain json.Number values
+	if data.Kind() == reflect.Map {
+		data = reflect.ValueOf(genericJSONValue(data.Interface()))
+	}
+
+	if !data.Type().AssignableTo(ptr.Type()) {
+		panic(fmt.Errorf("unable to use %v value %v as %v", data.Type(), data, ptr.Type()))
+	}
 	ptr.Set(data)
 }
 
@@ -149,6 +181,11 @@ func (c *Client) CastPtrToRef(dataVal reflect.Value) interface{} {
 		return wireDate
 	}
 
+	// In case we got a JSON value (or pointer to one), which is sent verbatim.
+	if json, isJSON := castPtrToJSON(dataVal); isJSON {
+		return json
+	}
+
 	switch dataVal.Kind() {
 	case reflect.Map:
 		result := api.WireMap{MapData: make(map[string]interface{})}
@@ -220,6 +257,14 @@ func (c *Client) CastPtrToRef(dataVal reflect.Value) interface{} {
 		if enumRef, isEnumRef := c.Types().TryRenderEnumRef(dataVal); isEnumRef {
 			return enumRef
 		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if num, err := castIntegerToNumber(dataVal); err != nil {
+			panic(err)
+		} else {
+			return num
+		}
 	}
 	return dataVal.Interface()
 }
@@ -242,6 +287,61 @@ func castPtrToDate(data reflect.Value) (wireDate api.WireDate, ok bool) {
 	return
 }
 
+// castPtrToJSON obtains an api.JSON from the provided reflect.Value if it
+// represents an api.JSON or *api.JSON value.
+func castPtrToJSON(data reflect.Value) (json api.JSON, ok bool) {
+	switch val := data.Interface().(type) {
+	case api.JSON:
+		return val, true
+	case *api.JSON:
+		return *val, true
+	default:
+		return nil, false
+	}
+}
+
+// castValToJSON converts the provided wire value into an api.JSON value. The
+// value is used verbatim, except for "$jsii.map" wrappers, which are removed.
+func castValToJSON(data reflect.Value) (api.JSON, error) {
+	if json, isJSON := data.Interface().(api.JSON); isJSON {
+		return json, nil
+	}
+	unwrapped := genericJSONValue(data.Interface())
+	if object, ok := unwrapped.(map[string]interface{}); ok {
+		return api.JSON(object), nil
+	}
+	return nil, fmt.Errorf("unable to use %v as a JSON object", data)
+}
+
+// genericJSONValue converts the provided value, decoded from the wire, into
+// the generic representation used by encoding/json by default: "$jsii.map"
+// wrappers are replaced with their content, and json.Number values are
+// converted to float64, unless they cannot be represented exactly (see
+// genericNumber).
+func genericJSONValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		if inner, isWireMap := val["$jsii.map"].(map[string]interface{}); isWireMap && len(val) == 1 {
+			val = inner
+		}
+		result := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			result[key] = genericJSONValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = genericJSONValue(item)
+		}
+		return result
+	case json.Number:
+		return genericNumber(val)
+	default:
+		return value
+	}
+}
+
 func castValToRef(data reflect.Value) (ref api.ObjectRef, ok bool) {
 	if data.Kind() == reflect.Map {
 		for _, k := range data.MapKeys() {
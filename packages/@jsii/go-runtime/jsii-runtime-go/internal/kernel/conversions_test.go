@@ -0,0 +1,187 @@
+package kernel
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+func TestCastPtrToRefPassesJSONVerbatim(t *testing.T) {
+	client := &Client{}
+
+	value := api.JSON{
+		"string": "Hello",
+		"nested": map[string]interface{}{"list": []interface{}{1.0, "two"}},
+	}
+	if result := client.CastPtrToRef(reflect.ValueOf(value)); !reflect.DeepEqual(result, value) {
+		t.Errorf("expected JSON value to be passed verbatim, got %#v", result)
+	}
+	if result := client.CastPtrToRef(reflect.ValueOf(&value)); !reflect.DeepEqual(result, value) {
+		t.Errorf("expected JSON pointer to be passed verbatim, got %#v", result)
+	}
+
+	// Other maps are still wrapped as jsii maps
+	if result := client.CastPtrToRef(reflect.ValueOf(map[string]interface{}(value))); reflect.TypeOf(result) != reflect.TypeOf(api.WireMap{}) {
+		t.Errorf("expected a WireMap, got %#v", result)
+	}
+}
+
+func TestCastAndSetToPtrDecodesJSON(t *testing.T) {
+	client := &Client{}
+
+	var result *api.JSON
+	client.CastAndSetToPtr(&result, map[string]interface{}{
+		"$jsii.map": map[string]interface{}{
+			"plain":   "value",
+			"wrapped": map[string]interface{}{"$jsii.map": map[string]interface{}{"key": 1337.0}},
+			"ref":     map[string]interface{}{"$jsii.byref": "Object@10000"},
+		},
+	})
+
+	expected := api.JSON{
+		"plain":   "value",
+		"wrapped": map[string]interface{}{"key": 1337.0},
+		"ref":     map[string]interface{}{"$jsii.byref": "Object@10000"},
+	}
+	if result == nil || !reflect.DeepEqual(*result, expected) {
+		t.Errorf("expected %#v, got %#v", expected, result)
+	}
+
+	var empty *api.JSON
+	client.CastAndSetToPtr(&empty, nil)
+	if empty != nil {
+		t.Errorf("expected nil, got %#v", empty)
+	}
+}
+
+func TestCastAndSetToPtrConvertsNumbers(t *testing.T) {
+	client := &Client{}
+
+	var i *int
+	client.CastAndSetToPtr(&i, json.Number("42"))
+	if i == nil || *i != 42 {
+		t.Errorf("expected 42, got %v", i)
+	}
+
+	var u8 uint8
+	client.CastAndSetToPtr(&u8, 255.0)
+	if u8 != 255 {
+		t.Errorf("expected 255, got %v", u8)
+	}
+
+	// Integers beyond 2^53 are not rounded when decoded as json.Number
+	var i64 int64
+	client.CastAndSetToPtr(&i64, json.Number("9007199254740993"))
+	if i64 != 9007199254740993 {
+		t.Errorf("expected 9007199254740993, got %v", i64)
+	}
+
+	var f *float64
+	client.CastAndSetToPtr(&f, json.Number("1.5"))
+	if f == nil || *f != 1.5 {
+		t.Errorf("expected 1.5, got %v", f)
+	}
+
+	// Generic targets still receive float64 values
+	var any interface{}
+	client.CastAndSetToPtr(&any, json.Number("1337"))
+	if any != 1337.0 {
+		t.Errorf("expected float64(1337), got %#v", any)
+	}
+	var anyList []interface{}
+	client.CastAndSetToPtr(&anyList, []interface{}{json.Number("1"), map[string]interface{}{"two": json.Number("2")}})
+	if expected := []interface{}{1.0, map[string]interface{}{"two": 2.0}}; !reflect.DeepEqual(anyList, expected) {
+		t.Errorf("expected %#v, got %#v", expected, anyList)
+	}
+}
+
+func TestCastAndSetToPtrPreservesLargeIntegers(t *testing.T) {
+	client := &Client{}
+	large := json.Number("9007199254740993")
+
+	var any interface{}
+	client.CastAndSetToPtr(&any, large)
+	if any != large {
+		t.Errorf("expected %#v, got %#v", large, any)
+	}
+
+	// Integers float64 can represent exactly are still converted.
+	client.CastAndSetToPtr(&any, json.Number("9007199254740992"))
+	if any != 9007199254740992.0 {
+		t.Errorf("expected float64(9007199254740992), got %#v", any)
+	}
+
+	var anyMap map[string]interface{}
+	client.CastAndSetToPtr(&anyMap, map[string]interface{}{"large": large})
+	if anyMap["large"] != large {
+		t.Errorf("expected %#v, got %#v", large, anyMap["large"])
+	}
+
+	var result *api.JSON
+	client.CastAndSetToPtr(&result, map[string]interface{}{"large": large, "list": []interface{}{large}})
+	if result == nil || (*result)["large"] != large || (*result)["list"].([]interface{})[0] != large {
+		t.Errorf("expected %#v to be preserved, got %#v", large, result)
+	}
+}
+
+func TestCastAndSetToPtrRejectsMismatchedTypes(t *testing.T) {
+	client := &Client{}
+
+	defer func() {
+		if err, isErr := recover().(error); !isErr || !strings.Contains(err.Error(), "unable to use json.Number value 42 as string") {
+			t.Errorf("expected a type error, got %v", err)
+		}
+	}()
+	var s string
+	client.CastAndSetToPtr(&s, json.Number("42"))
+}
+
+func TestCastAndSetToPtrRejectsInvalidIntegers(t *testing.T) {
+	client := &Client{}
+
+	for _, tc := range []struct {
+		name string
+		ptr  interface{}
+		data interface{}
+	}{
+		{"non-integral", new(int), json.Number("1.5")},
+		{"non-integral float", new(int32), 0.1},
+		{"overflow", new(int8), json.Number("128")},
+		{"negative unsigned", new(uint), json.Number("-1")},
+		{"out of int64 range", new(int64), json.Number("1e19")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+			}()
+			client.CastAndSetToPtr(tc.ptr, tc.data)
+		})
+	}
+}
+
+func TestCastPtrToRefChecksIntegerPrecision(t *testing.T) {
+	client := &Client{}
+
+	if result := client.CastPtrToRef(reflect.ValueOf(int64(maxSafeInteger))); result != int64(maxSafeInteger) {
+		t.Errorf("expected %v, got %#v", maxSafeInteger, result)
+	}
+	if result := client.CastPtrToRef(reflect.ValueOf(uint16(7))); result != uint16(7) {
+		t.Errorf("expected 7, got %#v", result)
+	}
+
+	for _, value := range []interface{}{int64(maxSafeInteger + 1), int64(-maxSafeInteger - 1), uint64(1 << 63)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for %v", value)
+				}
+			}()
+			client.CastPtrToRef(reflect.ValueOf(value))
+		}()
+	}
+}
@@ -1,20 +1,13 @@
 package kernel
 
-import "github.com/aws/jsii-runtime-go/internal/api"
+import "github.com/aws/jsii-runtime-go/protocol"
 
-type DelProps struct {
-	ObjRef api.ObjectRef `json:"objref"`
-}
-
-type DelResponse struct {
-	kernelResponse
-}
+type (
+	DelProps    = protocol.DelProps
+	DelResponse = protocol.DelResponse
+)
 
 func (c *Client) Del(props DelProps) (response DelResponse, err error) {
-	type request struct {
-		kernelRequest
-		DelProps
-	}
-	err = c.request(request{kernelRequest{"del"}, props}, &response)
+	err = c.request(protocol.NewRequest("del", props), &response)
 	return
 }
@@ -1,19 +1,16 @@
 package kernel
 
-type EndProps struct {
-	PromiseID *string `json:"promise_id"`
-}
+import "github.com/aws/jsii-runtime-go/protocol"
 
-type EndResponse struct {
-	kernelResponse
-	Result interface{} `json:"result"`
-}
+type (
+	EndProps    = protocol.EndProps
+	EndResponse = protocol.EndResponse
+)
 
 func (c *Client) End(props EndProps) (response EndResponse, err error) {
-	type request struct {
-		kernelRequest
-		EndProps
-	}
-	err = c.request(request{kernelRequest{"end"}, props}, &response)
+	// This blocks the kernel until the promise is resolved, so it must not
+	// happen while any callback is pending or being fulfilled.
+	err = c.exchange(protocol.NewRequest("end", props), &response, c.conversation.idle)
+	c.conversation.update(func() { c.conversation.promises-- })
 	return
 }
@@ -0,0 +1,45 @@
+package kernel
+
+import (
+	"sync"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel/process"
+)
+
+// EnvironmentPolicy controls which environment variables are forwarded to the
+// @jsii/kernel process (see process.EnvironmentPolicy).
+type EnvironmentPolicy = process.EnvironmentPolicy
+
+var (
+	environmentPolicy     *EnvironmentPolicy
+	environmentPolicyLock sync.Mutex
+)
+
+// GetEnvironmentPolicy returns the policy applied to the environment of the
+// @jsii/kernel processes started by clients. Unless SetEnvironmentPolicy was
+// called, it is described by the JSII_ENV_POLICY, JSII_ENV_ALLOW and
+// JSII_ENV_DENY environment variables, and an error is returned if these are
+// invalid.
+func GetEnvironmentPolicy() (EnvironmentPolicy, error) {
+	environmentPolicyLock.Lock()
+	defer environmentPolicyLock.Unlock()
+
+	if environmentPolicy != nil {
+		return *environmentPolicy, nil
+	}
+	return process.DefaultEnvironmentPolicy()
+}
+
+// SetEnvironmentPolicy changes the policy applied to the environment of the
+// @jsii/kernel processes started by clients. It only affects clients created
+// afterwards, and returns an error if the policy is invalid.
+func SetEnvironmentPolicy(policy EnvironmentPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	environmentPolicyLock.Lock()
+	defer environmentPolicyLock.Unlock()
+	environmentPolicy = &policy
+	return nil
+}
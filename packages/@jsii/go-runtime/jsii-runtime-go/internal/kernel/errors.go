@@ -0,0 +1,147 @@
+package kernel
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+)
+
+// faultErrorName is the name of errors raised by the @jsii/kernel when it is
+// used incorrectly (as opposed to errors raised by the JavaScript code).
+const faultErrorName = "@jsii/kernel.Fault"
+
+// GoError is an error that was returned by, or a panic that was raised in, go
+// code fulfilling a callback from the kernel (typically, an override). It is
+// reported to the kernel as a JavaScript error, and is the Cause of the
+// JavaScriptError received by the go code that triggered the callback, if the
+// JavaScript code did not handle it.
+type GoError struct {
+	// Type is the name of the go type of the error (or panic) value.
+	Type string
+	// Err is the original error (panic values that are not errors are
+	// converted using fmt.Errorf).
+	Err error
+	// Panic is true if the error was raised using panic.
+	Panic bool
+	// Stack is the go stack trace at the time the error was recovered.
+	Stack string
+
+	// callbackID identifies the callback that reported this error to the
+	// kernel. It is included in the reported message, so the error can be
+	// correlated with the JavaScriptError it eventually causes.
+	callbackID string
+}
+
+// newGoError creates a new GoError from the provided value, capturing the
+// current go stack trace. If the value already is a *GoError, it is returned
+// as-is, retaining its original stack trace.
+func newGoError(value interface{}, panicked bool) *GoError {
+	if goErr, ok := value.(*GoError); ok {
+		return goErr
+	}
+	err, ok := value.(error)
+	if !ok {
+		err = fmt.Errorf("%v", value)
+	}
+	return &GoError{
+		Type:  reflect.TypeOf(value).String(),
+		Err:   err,
+		Panic: panicked,
+		Stack: string(debug.Stack()),
+	}
+}
+
+func (e *GoError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *GoError) Unwrap() error {
+	return e.Err
+}
+
+// message renders the error message reported to the kernel, which includes
+// the go type name and stack trace, as these are otherwise lost, as well as
+// the token identifying the reporting callback.
+func (e *GoError) message() string {
+	kind := "error"
+	if e.Panic {
+		kind = "panic"
+	}
+	return fmt.Sprintf("%v (go %v of type %v %v)\n%v", e.Err, kind, e.Type, e.token(), e.Stack)
+}
+
+// token returns the marker identifying the callback that reported this error,
+// which is unique within a kernel process.
+func (e *GoError) token() string {
+	return fmt.Sprintf("[reported by %v]", e.callbackID)
+}
+
+// JavaScriptError is an error raised by the @jsii/kernel process in response to
+// a request, typically because the JavaScript code threw an exception.
+type JavaScriptError struct {
+	// Name is the name of the JavaScript error (e.g: @jsii/kernel.RuntimeError).
+	Name string
+	// Message is the JavaScript error message.
+	Message string
+	// Stack is the JavaScript stack trace of the error, if available.
+	Stack string
+	// GoStack is the go stack trace of the request that failed.
+	GoStack string
+	// Cause is the GoError that caused this error, if it was raised by go code
+	// fulfilling a callback for the failed request.
+	Cause *GoError
+}
+
+func (e *JavaScriptError) Error() string {
+	if e.Name == faultErrorName {
+		return fmt.Sprintf("JsiiError: %s", e.Name)
+	}
+	return e.Message
+}
+
+// Unwrap returns the GoError that caused this error, if any.
+func (e *JavaScriptError) Unwrap() error {
+	if e.Cause == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// Format implements fmt.Formatter. The %+v verb includes the JavaScript and go
+// stack traces, as well as those of the Cause, if any.
+func (e *JavaScriptError) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			fmt.Fprint(f, e.Error())
+			if e.Stack != "" {
+				fmt.Fprintf(f, "\n\nJavaScript stack:\n%v", e.Stack)
+			}
+			fmt.Fprintf(f, "\n\nGo stack:\n%v", e.GoStack)
+			if e.Cause != nil {
+				fmt.Fprintf(f, "\n\nCaused by go %v: %v\n%v", e.Cause.Type, e.Cause.Err, e.Cause.Stack)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprint(f, e.Error())
+	case 'q':
+		fmt.Fprintf(f, "%q", e.Error())
+	default:
+		fmt.Fprintf(f, "%%!%c(*kernel.JavaScriptError=%v)", verb, e.Error())
+	}
+}
+
+// findCause returns the last of the provided errors whose callback token is
+// included in this error's message, if any. Errors are correlated by callback,
+// as distinct callbacks may well report identical messages.
+func (e *JavaScriptError) findCause(reported []*GoError) *GoError {
+	for i := len(reported) - 1; i >= 0; i-- {
+		if strings.Contains(e.Message, reported[i].token()) {
+			return reported[i]
+		}
+	}
+	return nil
+}
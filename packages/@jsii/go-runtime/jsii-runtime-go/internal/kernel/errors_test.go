@@ -0,0 +1,110 @@
+package kernel
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+type failingReceiver struct{}
+
+func (r *failingReceiver) Explode(message *string) {
+	panic(errors.New(*message))
+}
+
+func (r *failingReceiver) Shout(message *string) {
+	panic(*message)
+}
+
+func (r *failingReceiver) Echo(message *string) *string {
+	return message
+}
+
+func TestCallbackErrors(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		objref := api.ObjectRef{InstanceID: "test.Receiver@1"}
+		if err := client.RegisterInstance(reflect.ValueOf(&failingReceiver{}), objref); err != nil {
+			t.Fatal(err)
+		}
+		callback := func(method string) (InvokeResponse, error) {
+			return client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{objref, method, "boom"}})
+		}
+
+		if result, err := callback("Echo"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		} else if result.Result != "boom" {
+			t.Errorf("expected boom, got %#v", result.Result)
+		}
+
+		for _, tc := range []struct {
+			method  string
+			errType string
+			panic   bool
+			frame   string
+		}{
+			{"Explode", "*errors.errorString", true, "(*failingReceiver).Explode"},
+			{"Shout", "string", true, "(*failingReceiver).Shout"},
+			{"Missing", "*errors.errorString", false, "(*callback).handle"},
+		} {
+			t.Run(tc.method, func(t *testing.T) {
+				_, err := callback(tc.method)
+
+				var jsErr *JavaScriptError
+				if !errors.As(err, &jsErr) {
+					t.Fatalf("expected a *JavaScriptError, got %#v", err)
+				}
+				if jsErr.Name != "@jsii/kernel.RuntimeError" || !strings.Contains(jsErr.Stack, "fake-kernel.js") {
+					t.Errorf("expected the JavaScript error details, got %v: %v", jsErr.Name, jsErr.Stack)
+				}
+				if !strings.Contains(jsErr.GoStack, "TestCallbackErrors") {
+					t.Errorf("expected the go stack of the caller, got %v", jsErr.GoStack)
+				}
+
+				var goErr *GoError
+				if !errors.As(err, &goErr) {
+					t.Fatalf("expected the cause to be a *GoError, got %#v", jsErr.Cause)
+				}
+				if goErr.Type != tc.errType || goErr.Panic != tc.panic {
+					t.Errorf("expected a %v (panic: %v), got %v (panic: %v)", tc.errType, tc.panic, goErr.Type, goErr.Panic)
+				}
+				if !strings.Contains(goErr.Stack, tc.frame) {
+					t.Errorf("expected the go stack to include %v, got %v", tc.frame, goErr.Stack)
+				}
+
+				detailed := fmt.Sprintf("%+v", err)
+				for _, part := range []string{"JavaScript stack:", "Go stack:", "Caused by go " + tc.errType} {
+					if !strings.Contains(detailed, part) {
+						t.Errorf("expected %q in %v", part, detailed)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestFindCauseCorrelatesByCallback(t *testing.T) {
+	// Both errors have the same message and were recovered at the same location,
+	// so only the callback that reported them tells them apart.
+	first := newGoError(errors.New("boom"), true)
+	second := *first
+	first.callbackID, second.callbackID = "jsii::callback::1", "jsii::callback::10"
+
+	jsErr := &JavaScriptError{Message: "Error: " + first.message()}
+	if cause := jsErr.findCause([]*GoError{first, &second}); cause != first {
+		t.Errorf("expected the cause to be the error reported by %v, got %#v", first.callbackID, cause)
+	}
+
+	jsErr = &JavaScriptError{Message: "Error: " + second.message()}
+	if cause := jsErr.findCause([]*GoError{&second, first}); cause != &second {
+		t.Errorf("expected the cause to be the error reported by %v, got %#v", second.callbackID, cause)
+	}
+
+	jsErr = &JavaScriptError{Message: "Error: boom (go panic of type *errors.errorString)"}
+	if cause := jsErr.findCause([]*GoError{first, &second}); cause != nil {
+		t.Errorf("expected no cause, got %#v", cause)
+	}
+}
@@ -0,0 +1,226 @@
+package kernel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/typeregistry"
+)
+
+// fakeKernelRef is the object the fakeKernel's "callback" and other methods are
+// invoked on, as invocations must target an object.
+var fakeKernelRef = api.ObjectRef{InstanceID: "test.Kernel@1"}
+
+// fakeKernel is a pretend @jsii/kernel process that serves a chain of nested
+// struct objects ("test.Level@1" through "test.Level@<depth>"), each having
+// string properties "field0" through "field7", and a "child" property
+// referencing the next level by reference. Invoking the "callback" method
+// with an object reference, a method name, and arguments invokes that method
+// on the object using a callback, and fails with a RuntimeError if the
+// callback completes with an error. Objects can be created with overrides,
+// which are then fulfilled using callbacks when invoked, read or written.
+// Invoking any other method returns the arguments it was called with. The
+// "callback" method can also be begun asynchronously, in which case an async
+// callback is queued for the "callbacks" API, and the promise is resolved once
+// it has been completed; any other method begun asynchronously resolves to its
+// arguments right away. The FAKE_KERNEL_FEATURES environment variable sets the
+// comma-separated features advertised in the hello message. The command for a
+// library's script runs the script name as a node one-liner, with FAKE_KERNEL
+// set to the library name in its environment. Loading a library reports its
+// name suffixed with the PID of the kernel process as the assembly name, and
+// stats report the kernel's actual memory usage.
+const fakeKernel = `
+const readline = require('readline');
+const [version, depth] = process.argv.slice(2);
+let callbacks = 0;
+const objects = new Map();
+const pending = [];
+const promises = new Map();
+let queued = [];
+
+function override(request, kind) {
+	const overrides = request.objref && objects.get(request.objref['$jsii.byref']);
+	const name = kind === 'method' ? request.method : request.property;
+	const found = overrides && overrides.find((o) => o[kind] === name);
+	if (!found) {
+		return false;
+	}
+	const callback = { cbid: 'jsii::callback::' + (++callbacks), cookie: found.cookie };
+	if (request.api === 'invoke') {
+		callback.invoke = { objref: request.objref, method: request.method, args: request.args };
+	} else if (request.api === 'get') {
+		callback.get = { objref: request.objref, property: request.property };
+	} else {
+		callback.set = { objref: request.objref, property: request.property, value: request.value };
+	}
+	pending.push(request.api);
+	console.log(JSON.stringify({ callback }));
+	return true;
+}
+
+const features = process.env.FAKE_KERNEL_FEATURES;
+console.log(JSON.stringify({ hello: '@fake/jsii-runtime@' + version, features: features != null ? features.split(',').filter((f) => f) : undefined }));
+
+readline.createInterface({ input: process.stdin }).on('line', (line) => {
+	const request = JSON.parse(line);
+	if (request.exit != null) {
+		process.exit(request.exit);
+	}
+	if (request.complete != null) {
+		const { err, result } = request.complete;
+		const api = pending.pop();
+		if (err) {
+			const stack = 'RuntimeError: ' + err + '\n    at Kernel.callback (fake-kernel.js)';
+			console.log(JSON.stringify({ error: err, name: '@jsii/kernel.RuntimeError', stack }));
+		} else if (api === 'get') {
+			console.log(JSON.stringify({ ok: { value: result } }));
+		} else if (api === 'set') {
+			console.log(JSON.stringify({ ok: {} }));
+		} else {
+			console.log(JSON.stringify({ ok: { result } }));
+		}
+		return;
+	}
+	if (request.api === 'begin') {
+		const promiseid = 'jsii::promise::' + (promises.size + 1);
+		if (request.method === 'callback') {
+			const [objref, method, ...args] = request.args;
+			const cbid = 'jsii::callback::' + (++callbacks);
+			queued.push({ cbid, cookie: method, invoke: { objref, method, args } });
+			promises.set(promiseid, { cbid });
+		} else {
+			promises.set(promiseid, { done: true, result: request.args });
+		}
+		console.log(JSON.stringify({ ok: { promiseid } }));
+		return;
+	}
+	if (request.api === 'callbacks') {
+		console.log(JSON.stringify({ ok: { callbacks: queued } }));
+		queued = [];
+		return;
+	}
+	if (request.api === 'complete') {
+		const promise = [...promises.values()].find((p) => p.cbid === request.cbid && !p.done);
+		if (!promise) {
+			console.log(JSON.stringify({ error: 'unknown callback: ' + request.cbid }));
+			return;
+		}
+		Object.assign(promise, { done: true, result: request.result, err: request.err });
+		console.log(JSON.stringify({ ok: { cbid: request.cbid } }));
+		return;
+	}
+	if (request.api === 'end') {
+		const promise = promises.get(request.promiseid);
+		if (!promise || !promise.done) {
+			console.log(JSON.stringify({ error: 'promise is not settled: ' + request.promiseid }));
+		} else if (promise.err) {
+			const stack = 'RuntimeError: ' + promise.err + '\n    at Kernel.end (fake-kernel.js)';
+			console.log(JSON.stringify({ error: promise.err, name: '@jsii/kernel.RuntimeError', stack }));
+		} else {
+			console.log(JSON.stringify({ ok: { result: promise.result } }));
+		}
+		return;
+	}
+	if (request.api === 'invoke' && request.method === 'callback') {
+		const [objref, method, ...args] = request.args;
+		pending.push('invoke');
+		console.log(JSON.stringify({ callback: { cbid: 'jsii::callback::' + (++callbacks), cookie: method, invoke: { objref, method, args } } }));
+		return;
+	}
+	if (request.api === 'getBinScriptCommand') {
+		// The "script" is a node one-liner, run with the provided arguments.
+		const args = ['-e', request.script, '--', ...(request.args || [])];
+		console.log(JSON.stringify({ ok: { command: process.execPath, args, env: { FAKE_KERNEL: request.assembly } } }));
+		return;
+	}
+	if (request.api === 'load') {
+		console.log(JSON.stringify({ ok: { assembly: request.name + '@' + process.pid, types: 0 } }));
+		return;
+	}
+	if (request.api === 'stats') {
+		const { rss, heapTotal, heapUsed, external } = process.memoryUsage();
+		console.log(JSON.stringify({ ok: { objectCount: objects.size, memory: { rss, heapTotal, heapUsed, external } } }));
+		return;
+	}
+	if (request.api === 'create') {
+		const instanceID = request.fqn + '@' + (10000 + objects.size);
+		objects.set(instanceID, request.overrides || []);
+		console.log(JSON.stringify({ ok: { '$jsii.byref': instanceID } }));
+		return;
+	}
+	if (override(request, request.api === 'invoke' ? 'method' : 'property')) {
+		return;
+	}
+	if (request.api === 'invoke') {
+		console.log(JSON.stringify({ ok: { result: request.args } }));
+		return;
+	}
+	if (request.api !== 'get') {
+		console.log(JSON.stringify({ error: 'unsupported api: ' + request.api }));
+		return;
+	}
+	const level = Number(request.objref['$jsii.byref'].split('@')[1]);
+	if (request.property === 'child') {
+		const value = level < Number(depth) ? { '$jsii.byref': 'test.Level@' + (level + 1) } : null;
+		console.log(JSON.stringify({ ok: { value } }));
+	} else if (/^field\d$/.test(request.property)) {
+		console.log(JSON.stringify({ ok: { value: request.property + '@' + level } }));
+	} else {
+		console.log(JSON.stringify({ error: 'no such property: ' + request.property }));
+	}
+});
+`
+
+type level struct {
+	Field0 *string `json:"field0"`
+	Field1 *string `json:"field1"`
+	Field2 *string `json:"field2"`
+	Field3 *string `json:"field3"`
+	Field4 *string `json:"field4"`
+	Field5 *string `json:"field5"`
+	Field6 *string `json:"field6"`
+	Field7 *string `json:"field7"`
+	Child  *level  `json:"child"`
+}
+
+// withFakeKernel starts a client backed by the fakeKernel, serving the
+// specified depth of nested structs, with the "test.Level" struct registered.
+func withFakeKernel(tb testing.TB, depth int, cb func(*Client)) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		tb.Skip("node is not available")
+	}
+
+	script, err := ioutil.TempFile("", "jsii-fake-kernel.*.js")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer os.Remove(script.Name())
+	if _, err := script.WriteString(fakeKernel); err != nil {
+		tb.Fatal(err)
+	}
+	script.Close()
+
+	oldJsiiRuntime := os.Getenv("JSII_RUNTIME")
+	os.Setenv("JSII_RUNTIME", fmt.Sprintf("%v %v %v %v", node, script.Name(), version, depth))
+	defer os.Setenv("JSII_RUNTIME", oldJsiiRuntime)
+
+	client, err := newClient()
+	if err != nil {
+		tb.Fatalf("client init failed: %v", err.Error())
+	}
+	defer client.close()
+
+	// Clean up after ourselves, so this test does not leave traces behind.
+	defer func() { types = typeregistry.New() }()
+	if err := client.Types().RegisterStruct("test.Level", reflect.TypeOf(level{})); err != nil {
+		tb.Fatal(err)
+	}
+
+	cb(client)
+}
@@ -0,0 +1,146 @@
+package kernel
+
+import (
+	"sync"
+
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+type CallbacksResponse = protocol.CallbacksResponse
+
+// callbacks lists the pending asynchronous callbacks (typically, invocations of
+// async method overrides). The caller must have acquired the conversation with
+// the kernel, and is responsible for completing all returned callbacks.
+func (c *Client) callbacks() (response CallbacksResponse, err error) {
+	err = c.send(protocol.NewRequest("callbacks", nil), &response)
+	return
+}
+
+// Future is the eventual result of an asynchronous method invocation.
+type Future struct {
+	done   chan struct{}
+	result EndResponse
+	err    error
+}
+
+// Done returns a channel that is closed once the result is available.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result waits for the asynchronous method to complete, and returns its result.
+func (f *Future) Result() (EndResponse, error) {
+	<-f.done
+	return f.result, f.err
+}
+
+// InvokeAsync begins the invocation of an asynchronous method, and returns a
+// Future for its result. The result is awaited in a new goroutine, which
+// fulfills asynchronous callbacks (e.g: async method overrides) in the
+// meantime, each in its own goroutine, so that they can block without
+// preventing other work with the kernel from proceeding.
+func (c *Client) InvokeAsync(props InvokeProps) (*Future, error) {
+	begin, err := c.Begin(BeginProps{Method: &props.Method, Arguments: props.Arguments, ObjRef: props.ObjRef})
+	if err != nil {
+		return nil, err
+	}
+
+	future := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(future.done)
+		future.result, future.err = c.await(*begin.PromiseID)
+	}()
+	return future, nil
+}
+
+// asyncErrors collects the errors raised while fulfilling asynchronous
+// callbacks for a given promise.
+type asyncErrors struct {
+	mutex sync.Mutex
+	// reported are the errors raised by go code, which were reported to the
+	// kernel.
+	reported []*GoError
+	// failed is the first error that prevented a callback's completion from
+	// being reported to the kernel.
+	failed error
+}
+
+// await fulfills asynchronous callbacks until none are left, then waits for the
+// promise with the provided ID to be resolved, and returns its result.
+func (c *Client) await(promiseID string) (result EndResponse, err error) {
+	errs := &asyncErrors{}
+	conversation := &c.conversation
+
+	ready := conversation.noCallbacks
+	for {
+		conversation.acquire(ready)
+
+		var pending CallbacksResponse
+		if pending, err = c.callbacks(); err != nil {
+			conversation.release()
+			return
+		}
+
+		if count := len(pending.Callbacks); count > 0 {
+			conversation.update(func() {
+				conversation.async += count
+				conversation.busy = false
+			})
+			for _, cb := range pending.Callbacks {
+				go c.fulfillAsync(callback(cb), errs)
+			}
+			ready = conversation.noCallbacks
+			continue
+		}
+
+		if inFlight := conversation.asyncCount(); inFlight > 0 {
+			// Callbacks are still being fulfilled, and their completion may result
+			// in new callbacks being queued, so we look again after any completes.
+			conversation.release()
+			ready = func() bool { return conversation.noCallbacks() && conversation.async < inFlight }
+			continue
+		}
+
+		// Nothing is left to do but waiting for the promise to be resolved. This
+		// blocks the kernel, so it must only happen once no callback is pending.
+		err = c.send(protocol.NewRequest("end", EndProps{PromiseID: &promiseID}), &result)
+		conversation.update(func() {
+			conversation.promises--
+			conversation.busy = false
+		})
+		break
+	}
+
+	errs.mutex.Lock()
+	defer errs.mutex.Unlock()
+	if jsErr, ok := err.(*JavaScriptError); ok {
+		jsErr.Cause = jsErr.findCause(errs.reported)
+	}
+	if err == nil && errs.failed != nil {
+		err = errs.failed
+	}
+	return
+}
+
+// fulfillAsync executes the provided asynchronous callback, and notifies the
+// kernel of its completion.
+func (c *Client) fulfillAsync(cb callback, errs *asyncErrors) {
+	defer c.conversation.update(func() { c.conversation.async-- })
+
+	outcome, goErr := c.fulfill(&cb, []CallbackFrame{cb.frame()})
+
+	props := CompleteProps{CallbackID: &outcome.CallbackID, Result: outcome.Result}
+	if outcome.Error != "" {
+		props.Error = &outcome.Error
+	}
+	_, err := c.Complete(props)
+
+	errs.mutex.Lock()
+	defer errs.mutex.Unlock()
+	if goErr != nil {
+		errs.reported = append(errs.reported, goErr)
+	}
+	if err != nil && errs.failed == nil {
+		errs.failed = err
+	}
+}
@@ -0,0 +1,158 @@
+package kernel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+type asyncReceiver struct {
+	client  *Client
+	started chan struct{}
+	release chan struct{}
+}
+
+// Double blocks until released, then doubles its argument after making a
+// round-trip to the kernel.
+func (r *asyncReceiver) Double(value *float64) *float64 {
+	r.started <- struct{}{}
+	<-r.release
+
+	res, err := r.client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "echo", Arguments: []interface{}{*value}})
+	if err != nil {
+		panic(err)
+	}
+	var echoed float64
+	r.client.CastAndSetToPtr(&echoed, res.Result.([]interface{})[0])
+
+	doubled := echoed * 2
+	return &doubled
+}
+
+func (r *asyncReceiver) Fail(message *string) *string {
+	panic(errors.New(*message))
+}
+
+func TestInvokeAsync(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		receiver := &asyncReceiver{client: client, started: make(chan struct{}), release: make(chan struct{})}
+		objref := api.ObjectRef{InstanceID: "test.Receiver@1"}
+		if err := client.RegisterInstance(reflect.ValueOf(receiver), objref); err != nil {
+			t.Fatal(err)
+		}
+
+		futures := make([]*Future, 3)
+		for i := range futures {
+			future, err := client.InvokeAsync(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{objref, "Double", i + 1}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			futures[i] = future
+		}
+		for range futures {
+			<-receiver.started
+		}
+
+		// All overrides are blocked, yet the kernel remains available...
+		if res, err := client.Get(GetProps{ObjRef: api.ObjectRef{InstanceID: "test.Level@1"}, Property: "field0"}); err != nil {
+			t.Fatal(err)
+		} else if res.Value != "field0@1" {
+			t.Errorf("expected field0@1, got %#v", res.Value)
+		}
+		for _, future := range futures {
+			select {
+			case <-future.Done():
+				t.Fatal("expected the future to be pending")
+			default:
+			}
+		}
+
+		close(receiver.release)
+		for i, future := range futures {
+			select {
+			case <-future.Done():
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for the future")
+			}
+			res, err := future.Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := json.Number(fmt.Sprint(2 * (i + 1))); res.Result != expected {
+				t.Errorf("expected %v, got %#v", expected, res.Result)
+			}
+		}
+	})
+}
+
+func TestInvokeAsyncErrors(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		objref := api.ObjectRef{InstanceID: "test.Receiver@1"}
+		if err := client.RegisterInstance(reflect.ValueOf(&asyncReceiver{}), objref); err != nil {
+			t.Fatal(err)
+		}
+
+		future, err := client.InvokeAsync(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{objref, "Fail", "boom"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = future.Result()
+
+		var jsErr *JavaScriptError
+		if !errors.As(err, &jsErr) {
+			t.Fatalf("expected a *JavaScriptError, got %#v", err)
+		}
+		var goErr *GoError
+		if !errors.As(err, &goErr) {
+			t.Fatalf("expected the cause to be a *GoError, got %#v", jsErr.Cause)
+		}
+		if goErr.Err.Error() != "boom" || !goErr.Panic {
+			t.Errorf("expected a panic with boom, got %v", goErr)
+		}
+	})
+}
+
+func TestConversationOrdersCallbacks(t *testing.T) {
+	var v conversation
+	var order []string
+	var wg sync.WaitGroup
+
+	v.acquire(nil)
+	v.beginCallback(&callback{CallbackID: "outer"})
+	v.acquire(nil)
+	v.beginCallback(&callback{CallbackID: "inner"})
+
+	// The outer callback completes first, but must wait for the inner one.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v.endCallback("outer")
+		order = append(order, "outer")
+		v.release()
+	}()
+
+	// Asynchronous operations must wait for all callbacks to complete.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v.acquire(v.noCallbacks)
+		order = append(order, "async")
+		v.release()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	v.endCallback("inner")
+	order = append(order, "inner")
+	v.release()
+	wg.Wait()
+
+	if expected := []string{"inner", "outer", "async"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected %v, got %v", expected, order)
+	}
+}
@@ -0,0 +1,15 @@
+package kernel
+
+import "github.com/aws/jsii-runtime-go/protocol"
+
+type (
+	GetScriptCommandProps    = protocol.GetScriptCommandProps
+	GetScriptCommandResponse = protocol.GetScriptCommandResponse
+)
+
+// GetScriptCommand returns how to run a script from the "bin" section of a
+// loaded library's package.json, so that it can be run by the caller.
+func (c *Client) GetScriptCommand(props GetScriptCommandProps) (response GetScriptCommandResponse, err error) {
+	err = c.request(protocol.NewRequest("getBinScriptCommand", props), &response)
+	return
+}
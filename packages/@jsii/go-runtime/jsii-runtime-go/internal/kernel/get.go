@@ -1,43 +1,69 @@
 package kernel
 
-import "github.com/aws/jsii-runtime-go/internal/api"
+import (
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/protocol"
+)
 
-type GetProps struct {
-	Property string        `json:"property"`
-	ObjRef   api.ObjectRef `json:"objref"`
-}
+type (
+	GetProps       = protocol.GetProps
+	StaticGetProps = protocol.StaticGetProps
+	GetResponse    = protocol.GetResponse
+)
 
-type StaticGetProps struct {
-	FQN      api.FQN `json:"fqn"`
-	Property string  `json:"property"`
+func (c *Client) Get(props GetProps) (response GetResponse, err error) {
+	err = c.request(protocol.NewRequest("get", props), &response)
+	return
 }
 
-type GetResponse struct {
-	kernelResponse
-	Value interface{} `json:"value"`
-}
+// GetAll reads several properties of the same object. The requests are
+// pipelined to the @jsii/kernel process, so that reading any number of
+// properties costs a single round-trip, instead of one per property. The
+// returned responses are in the same order as the requested properties. If
+// any of the reads failed, the error of the first failed read is returned.
+func (c *Client) GetAll(objref api.ObjectRef, properties []string) (responses []GetResponse, err error) {
+	if err = c.abandoned.check(); err != nil {
+		return
+	}
 
-func (c *Client) Get(props GetProps) (response GetResponse, err error) {
-	type request struct {
-		kernelRequest
-		GetProps
+	responses = make([]GetResponse, len(properties))
+	reqs := make([]protocol.Request, len(properties))
+	requests := make([]interface{}, len(properties))
+	envs := make([]response, len(properties))
+	envPtrs := make([]interface{}, len(properties))
+	for i, property := range properties {
+		reqs[i] = protocol.NewRequest("get", GetProps{Property: property, ObjRef: objref})
+		requests[i] = reqs[i]
+		envs[i] = newResponse(&responses[i])
+		envPtrs[i] = &envs[i]
 	}
-	err = c.request(request{kernelRequest{"get"}, props}, &response)
-	return
-}
 
-func (c *Client) SGet(props StaticGetProps) (response GetResponse, err error) {
-	type request struct {
-		kernelRequest
-		StaticGetProps
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+
+	// The same preconditions apply as for requests sent one at a time.
+	if err = c.prepare(reqs...); err != nil {
+		return
+	}
+	errs := c.process.RequestPipelined(requests, envPtrs)
+
+	// While it waits for a callback to complete, the kernel keeps processing
+	// the requests that were pipelined after the one that triggered it. Pending
+	// callbacks are hence nested, and must be completed from the innermost (that
+	// is, the last one) outwards, so responses are handled in reverse order.
+	for i := len(properties) - 1; i >= 0; i-- {
+		if errs != nil && errs[i] != nil {
+			err = errs[i]
+			continue
+		}
+		if handleErr := c.handleResponse(&envs[i], &responses[i]); handleErr != nil {
+			err = handleErr
+		}
 	}
-	err = c.request(request{kernelRequest{"sget"}, props}, &response)
 	return
 }
 
-// UnmarshalJSON provides custom unmarshalling implementation for response
-// structs. Creating new types is required in order to avoid infinite recursion.
-func (r *GetResponse) UnmarshalJSON(data []byte) error {
-	type response GetResponse
-	return unmarshalKernelResponse(data, (*response)(r), r)
+func (c *Client) SGet(props StaticGetProps) (response GetResponse, err error) {
+	err = c.request(protocol.NewRequest("sget", props), &response)
+	return
 }
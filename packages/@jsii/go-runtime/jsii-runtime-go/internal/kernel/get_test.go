@@ -0,0 +1,124 @@
+package kernel
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+func TestGetAll(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		res, err := client.GetAll(api.ObjectRef{InstanceID: "test.Level@1"}, []string{"field3", "child", "field1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 3 || res[0].Value != "field3@1" || res[1].Value != nil || res[2].Value != "field1@1" {
+			t.Errorf("unexpected responses: %v", res)
+		}
+
+		// Errors are reported, and do not de-synchronize the response stream.
+		if _, err := client.GetAll(api.ObjectRef{InstanceID: "test.Level@1"}, []string{"field0", "nope", "field2"}); err == nil {
+			t.Error("expected an error, but none was returned")
+		}
+		if res, err := client.GetAll(api.ObjectRef{InstanceID: "test.Level@1"}, []string{"field4"}); err != nil {
+			t.Fatal(err)
+		} else if res[0].Value != "field4@1" {
+			t.Errorf("expected field4@1, got %v", res[0].Value)
+		}
+	})
+}
+
+func TestCastAndSetToPtrStructByReference(t *testing.T) {
+	withFakeKernel(t, 3, func(client *Client) {
+		var result *level
+		client.CastAndSetToPtr(&result, map[string]interface{}{"$jsii.byref": "test.Level@1"})
+
+		for depth := 1; depth <= 3; depth++ {
+			if result == nil {
+				t.Fatalf("missing level %d", depth)
+			}
+			if expected := fmt.Sprintf("field7@%d", depth); result.Field7 == nil || *result.Field7 != expected {
+				t.Errorf("expected %v, got %v", expected, result.Field7)
+			}
+			result = result.Child
+		}
+		if result != nil {
+			t.Errorf("expected no level past 3, got %v", result)
+		}
+	})
+}
+
+// roundTrips counts the round-trips in a trace: a round-trip begins whenever a
+// request is sent after a response was received (or first).
+type roundTrips struct {
+	count   int
+	sending bool
+}
+
+func (r *roundTrips) Write(line []byte) (int, error) {
+	sent := bytes.HasPrefix(line, []byte(protocol.SentPrefix))
+	if sent && !r.sending {
+		r.count++
+	}
+	r.sending = sent
+	return len(line), nil
+}
+
+// BenchmarkStructByReference compares de-referencing a nested struct one
+// property at a time (one round-trip per property) with the pipelined
+// approach used by CastAndSetToPtr (one round-trip per struct). The round-trips
+// are counted from the trace of the messages exchanged with the kernel.
+func BenchmarkStructByReference(b *testing.B) {
+	for _, depth := range []int{1, 4, 16} {
+		withFakeKernel(b, depth, func(client *Client) {
+			ref := map[string]interface{}{"$jsii.byref": "test.Level@1"}
+
+			b.Run(fmt.Sprintf("depth=%d/sequential", depth), func(b *testing.B) {
+				counter := &roundTrips{}
+				client.SetTrace(counter)
+				defer client.SetTrace(nil)
+
+				for i := 0; i < b.N; i++ {
+					var result level
+					getSequentially(b, client, api.ObjectRef{InstanceID: "test.Level@1"}, reflect.ValueOf(&result).Elem())
+				}
+				b.ReportMetric(float64(counter.count)/float64(b.N), "round-trips/op")
+			})
+
+			b.Run(fmt.Sprintf("depth=%d/pipelined", depth), func(b *testing.B) {
+				counter := &roundTrips{}
+				client.SetTrace(counter)
+				defer client.SetTrace(nil)
+
+				for i := 0; i < b.N; i++ {
+					var result *level
+					client.CastAndSetToPtr(&result, ref)
+				}
+				b.ReportMetric(float64(counter.count)/float64(b.N), "round-trips/op")
+			})
+		})
+	}
+}
+
+// getSequentially de-references a struct by reading its properties one at a
+// time, with one round-trip per property.
+func getSequentially(b *testing.B, client *Client, ref api.ObjectRef, ptr reflect.Value) {
+	fields, _, _ := client.Types().StructFields(ptr.Type())
+	for _, field := range fields {
+		got, err := client.Get(GetProps{Property: field.Tag.Get("json"), ObjRef: ref})
+		if err != nil {
+			b.Fatal(err)
+		}
+		fieldVal := ptr.FieldByIndex(field.Index)
+		if childRef, isRef := castValToRef(reflect.ValueOf(got.Value)); isRef {
+			fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
+			getSequentially(b, client, childRef, fieldVal.Elem())
+		} else {
+			client.castAndSetToPtr(fieldVal, reflect.ValueOf(got.Value))
+		}
+	}
+}
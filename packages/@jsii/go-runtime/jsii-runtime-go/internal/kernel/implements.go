@@ -0,0 +1,77 @@
+package kernel
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+// AssumeInterface records the provided interface as implemented by the object
+// designated by ref, without verifying this is actually the case. This is used
+// by UnsafeCast, which trusts the caller.
+func (c *Client) AssumeInterface(ref api.ObjectRef, iface api.FQN) {
+	c.objects.AssumeInterface(ref.InstanceID, iface)
+}
+
+// VerifyImplements checks whether the object designated by ref implements the
+// provided go interface type. This is determined using the object's dynamic
+// type (as designated by the kernel-assigned instance ID) and the interfaces it
+// was declared to implement, as well as all of their supertypes, as declared by
+// the metadata of the loaded assemblies. These need not have a registered go
+// type, so objects of types that are not exported to go (and anonymous objects)
+// can be verified, too. Interfaces that were merely assumed (see
+// AssumeInterface) are not considered.
+//
+// Returns an error describing the object's known types if the interface is
+// not implemented.
+func (c *Client) VerifyImplements(ref api.ObjectRef, iface reflect.Type) error {
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("%v is not an interface type", iface)
+	}
+
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+	hierarchy := c.typeHierarchy()
+
+	pending := c.objects.DeclaredInterfaces(ref.InstanceID)
+	if typeFQN, ok := ref.TryTypeFQN(); ok {
+		pending = append([]api.FQN{typeFQN}, pending...)
+	}
+	targetFQN, hasFQN := c.Types().InterfaceFQN(iface)
+
+	seen := make(map[api.FQN]bool)
+	for len(pending) > 0 {
+		fqn := pending[0]
+		pending = pending[1:]
+		if seen[fqn] {
+			continue
+		}
+		seen[fqn] = true
+
+		if hasFQN && fqn == targetFQN {
+			return nil
+		}
+		if typ, found := c.Types().FindType(fqn); found && typ.Kind() == reflect.Interface && typ.Implements(iface) {
+			return nil
+		}
+		pending = append(pending, hierarchy[fqn]...)
+	}
+
+	target := iface.String()
+	if hasFQN {
+		target = fmt.Sprintf("%v (%v)", targetFQN, iface)
+	}
+
+	if len(seen) == 0 {
+		return fmt.Errorf("%v does not implement %v: its type is unknown", ref.InstanceID, target)
+	}
+	known := make([]string, 0, len(seen))
+	for fqn := range seen {
+		known = append(known, string(fqn))
+	}
+	sort.Strings(known)
+	return fmt.Errorf("%v does not implement %v (known types: %v)", ref.InstanceID, target, strings.Join(known, ", "))
+}
@@ -0,0 +1,91 @@
+package kernel
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+type namedInterface interface {
+	Name() *string
+}
+
+// makeTarball returns a gzipped npm package tarball holding the provided files,
+// relative to the package directory.
+func makeTarball(t *testing.T, files map[string][]byte) []byte {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	writer := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := writer.WriteHeader(&tar.Header{Name: "package/" + name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.Close()
+	gz.Close()
+	return buffer.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	return buffer.Bytes()
+}
+
+func TestVerifyImplementsUsesAssemblyMetadata(t *testing.T) {
+	// test.Impl (which has no go type) extends test.Base, which implements
+	// test.IGreeter, which extends test.INamed. The latter is in another
+	// assembly, whose .jsii file is a redirect to a compressed assembly.
+	greeter := []byte(`{"types":{
+		"test.Base":{"kind":"class","interfaces":["test.IGreeter"]},
+		"test.Impl":{"kind":"class","base":"test.Base"},
+		"test.IGreeter":{"kind":"interface","interfaces":["test.INamed"]},
+		"test.Other":{"kind":"class"}
+	}}`)
+	named := []byte(`{"types":{"test.INamed":{"kind":"interface"}}}`)
+
+	withFakeKernel(t, 1, func(client *Client) {
+		if err := client.Types().RegisterInterface("test.INamed", reflect.TypeOf((*namedInterface)(nil)).Elem(), []api.Override{}, func() interface{} { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Load(LoadProps{Name: "named", Version: "1.0.0"}, makeTarball(t, map[string][]byte{
+			".jsii":    []byte(`{"schema":"jsii/file-redirect","compression":"gzip","filename":".jsii.gz"}`),
+			".jsii.gz": gzipped(t, named),
+		})); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Load(LoadProps{Name: "greeter", Version: "1.0.0"}, makeTarball(t, map[string][]byte{".jsii": greeter})); err != nil {
+			t.Fatal(err)
+		}
+
+		iface := reflect.TypeOf((*namedInterface)(nil)).Elem()
+		if err := client.VerifyImplements(api.ObjectRef{InstanceID: "test.Impl@10000"}, iface); err != nil {
+			t.Errorf("expected test.Impl to implement test.INamed, got %v", err)
+		}
+
+		anonymous := api.ObjectRef{InstanceID: "Object@10001", Interfaces: []api.FQN{"test.IGreeter"}}
+		if err := client.RegisterInstance(reflect.ValueOf(&struct{ api.ObjectRef }{}), anonymous); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.VerifyImplements(anonymous, iface); err != nil {
+			t.Errorf("expected an anonymous test.IGreeter to implement test.INamed, got %v", err)
+		}
+
+		err := client.VerifyImplements(api.ObjectRef{InstanceID: "test.Other@10002"}, iface)
+		if err == nil || !strings.Contains(err.Error(), "does not implement test.INamed") {
+			t.Errorf("expected test.Other not to implement test.INamed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+package kernel
+
+import "github.com/aws/jsii-runtime-go/protocol"
+
+type (
+	InvokeScriptProps    = protocol.InvokeScriptProps
+	InvokeScriptResponse = protocol.InvokeScriptResponse
+)
+
+// InvokeScript runs a script from the "bin" section of a loaded library's
+// package.json within the @jsii/kernel process, and returns its outcome once
+// it has exited. The script's output is captured as text.
+func (c *Client) InvokeScript(props InvokeScriptProps) (response InvokeScriptResponse, err error) {
+	err = c.request(protocol.NewRequest("invokeBinScript", props), &response)
+	return
+}
@@ -1,40 +1,55 @@
 package kernel
 
 import (
-	"encoding/json"
-	"errors"
+	"runtime/debug"
+
+	"github.com/aws/jsii-runtime-go/protocol"
 )
 
-// unmarshalKernelResponse performs custom unmarshaling for kernel responses, checks for presence of `error` key on json
-// and returns if present.
-//
-// The uresult parameter may be passed to json.Unmarshal so if unmarshalKernelResponse is called within a custom
-// UnmarshalJSON function, it must be a type alias (otherwise, this will recurse into itself until the stack is full).
-// The result parameter must point to the original result (with the original type). This unfortunate duplication is
-// necessary for the proper handling of in-line callbacks.
-func unmarshalKernelResponse(data []byte, uresult kernelResponder, result kernelResponder) error {
-	datacopy := make([]byte, len(data))
-	copy(datacopy, data)
+// response is the envelope of all messages received from the @jsii/kernel
+// process in response to a request.
+type response = protocol.Response
 
-	var response map[string]json.RawMessage
-	if err := json.Unmarshal(datacopy, &response); err != nil {
-		return err
-	}
+// newResponse creates a response envelope that decodes successful payloads
+// into the provided result.
+func newResponse(result protocol.Result) response {
+	return protocol.NewResponse(result)
+}
 
-	if err, ok := response["error"]; ok {
-		return errors.New(string(err))
+// kernelError converts an error response from the kernel to a
+// *JavaScriptError. Other errors are returned as they are.
+func kernelError(err error) error {
+	if kernelErr, ok := err.(*protocol.Error); ok {
+		return &JavaScriptError{Name: kernelErr.Name, Message: kernelErr.Message, Stack: kernelErr.Stack, GoStack: string(debug.Stack())}
 	}
+	return err
+}
 
-	// In-line callback requests interrupt the current flow, the callback handling
-	// logic will resume this handling once the callback request has been fulfilled.
-	if raw, ok := response["callback"]; ok {
-		callback := callback{}
-		if err := json.Unmarshal(raw, &callback); err != nil {
-			return err
+// handleResponse processes a decoded response envelope (see
+// protocol.Client.Resume). In-line callback requests interrupt the current
+// flow: they are fulfilled (and the kernel notified of their completion) until
+// the final response for the original request is received, which is then
+// decoded into result. Callbacks are subject to the current CallbackLimits.
+// Errors raised while fulfilling callbacks are reported to the kernel, and
+// become the Cause of the final error if the JavaScript code did not handle
+// them. The conversation with the kernel must have been acquired by the caller,
+// and it is released while callbacks are being fulfilled.
+func (c *Client) handleResponse(env *response, result protocol.Result) error {
+	var reported []*GoError
+	wire := c.wire.WithHandler(func(pending protocol.Callback) callbackResult {
+		cb := callback(pending)
+		chain := c.conversation.beginCallback(&cb)
+		outcome, goErr := c.fulfill(&cb, chain)
+		c.conversation.endCallback(cb.CallbackID)
+		if goErr != nil {
+			reported = append(reported, goErr)
 		}
+		return outcome
+	})
 
-		return callback.handle(result)
+	err := kernelError(wire.Resume(env, result))
+	if jsErr, ok := err.(*JavaScriptError); ok {
+		jsErr.Cause = jsErr.findCause(reported)
 	}
-
-	return json.Unmarshal(response["ok"], uresult)
+	return err
 }
@@ -0,0 +1,147 @@
+package kernel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+func TestHandleResponse(t *testing.T) {
+	decode := func(message string, result protocol.Result) error {
+		env := newResponse(result)
+		if err := json.Unmarshal([]byte(message), &env); err != nil {
+			t.Fatal(err)
+		}
+		// Responses without callbacks do not need a transport.
+		client := &Client{wire: protocol.Attach(nil, protocol.Hello{}, nil)}
+		return client.handleResponse(&env, result)
+	}
+
+	var invoked InvokeResponse
+	if err := decode(`{"ok":{"result":[1,"two",{"$jsii.byref":"Object@10000"}]}}`, &invoked); err != nil {
+		t.Fatal(err)
+	}
+	if result, ok := invoked.Result.([]interface{}); !ok || len(result) != 3 {
+		t.Errorf("unexpected result: %#v", invoked.Result)
+	}
+
+	var created CreateResponse
+	if err := decode(`{"ok":{"$jsii.byref":"Object@10001"}}`, &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.InstanceID != "Object@10001" {
+		t.Errorf("unexpected instance ID: %v", created.InstanceID)
+	}
+
+	if err := decode(`{"error":"Boom!","stack":"Error: Boom!\n    at <anonymous>"}`, &invoked); err == nil || err.Error() != "Boom!" {
+		t.Errorf("expected error Boom!, got %v", err)
+	}
+}
+
+// invokeResponseStream returns a stream of n newline-delimited invoke
+// responses, as sent by the @jsii/kernel process.
+func invokeResponseStream(n int) []byte {
+	var buffer bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buffer, `{"ok":{"result":{"$jsii.map":{"name":"item-%d","count":%d,"tags":["a","b","c"],"ref":{"$jsii.byref":"jsii-calc.Calculator@%d"}}}}}`+"\n", i, i, 10000+i)
+	}
+	return buffer.Bytes()
+}
+
+// BenchmarkResponseDecoding compares the single-pass decoding of kernel
+// responses with the multi-pass approach previously used by the runtime.
+func BenchmarkResponseDecoding(b *testing.B) {
+	const batch = 100
+	stream := invokeResponseStream(batch)
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			decoder := json.NewDecoder(bytes.NewReader(stream))
+			for j := 0; j < batch; j++ {
+				var result legacyInvokeResponse
+				if err := legacyReadResponse(decoder, &result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("single-pass", func(b *testing.B) {
+		b.ReportAllocs()
+		client := &Client{wire: protocol.Attach(nil, protocol.Hello{}, nil)}
+		for i := 0; i < b.N; i++ {
+			decoder := json.NewDecoder(bytes.NewReader(stream))
+			for j := 0; j < batch; j++ {
+				var result InvokeResponse
+				env := newResponse(&result)
+				if err := decoder.Decode(&env); err != nil {
+					b.Fatal(err)
+				}
+				if err := client.handleResponse(&env, &result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkInvoke measures a high volume of invoke round-trips against a fake
+// kernel process.
+func BenchmarkInvoke(b *testing.B) {
+	withFakeKernel(b, 1, func(client *Client) {
+		props := InvokeProps{
+			Method:    "echo",
+			Arguments: []interface{}{"Hello", 1337.0, map[string]interface{}{"$jsii.map": map[string]interface{}{"key": strings.Repeat("value", 10)}}},
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.Invoke(props); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// legacyInvokeResponse decodes like kernel responses used to, for comparison.
+type legacyInvokeResponse InvokeResponse
+
+func (r *legacyInvokeResponse) UnmarshalJSON(data []byte) error {
+	datacopy := make([]byte, len(data))
+	copy(datacopy, data)
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(datacopy, &response); err != nil {
+		return err
+	}
+	if err, ok := response["error"]; ok {
+		return errors.New(string(err))
+	}
+
+	type alias InvokeResponse
+	return json.Unmarshal(response["ok"], (*alias)(r))
+}
+
+// legacyReadResponse reads responses like the child process used to, for
+// comparison.
+func legacyReadResponse(decoder *json.Decoder, into interface{}) error {
+	var raw json.RawMessage
+	var respmap map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &respmap); err != nil {
+		return err
+	}
+	if _, ok := respmap["error"]; ok {
+		return errors.New("error response")
+	}
+	return json.Unmarshal(raw, &into)
+}
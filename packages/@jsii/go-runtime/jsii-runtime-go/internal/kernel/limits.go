@@ -0,0 +1,277 @@
+package kernel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+const (
+	// JSII_CALLBACK_MAX_DEPTH is the name of the environment variable that
+	// overrides the default CallbackLimits.MaxDepth.
+	JSII_CALLBACK_MAX_DEPTH = "JSII_CALLBACK_MAX_DEPTH"
+	// JSII_CALLBACK_TIMEOUT is the name of the environment variable that sets
+	// the default CallbackLimits.Timeout, using the time.ParseDuration format.
+	JSII_CALLBACK_TIMEOUT = "JSII_CALLBACK_TIMEOUT"
+
+	// DefaultCallbackMaxDepth is the default limit on nested callbacks, which is
+	// well within what both the go and JavaScript stacks can accommodate.
+	DefaultCallbackMaxDepth = 256
+)
+
+// CallbackLimits bounds the fulfillment of callbacks from the kernel (such as
+// calls to method overrides), so that runaway recursion between JavaScript and
+// go, or go code that blocks forever, fail with a diagnostic error instead of
+// crashing or hanging the process.
+type CallbackLimits struct {
+	// MaxDepth is the maximum number of nested callbacks. A callback that would
+	// exceed it fails with a CallbackDepthError without calling any go code.
+	// Zero or less means no limit.
+	MaxDepth int
+	// Timeout is how long a callback may take to complete before it fails with
+	// a CallbackTimeoutError. The go code fulfilling it keeps running, but its
+	// outcome is ignored, and the requests it makes to the kernel afterwards
+	// fail (those made by goroutines it started are not detected). Zero or less
+	// means no timeout.
+	Timeout time.Duration
+}
+
+var (
+	callbackLimits     CallbackLimits
+	callbackLimitsOnce sync.Once
+	callbackLimitsLock sync.RWMutex
+)
+
+// GetCallbackLimits returns the limits currently applied to callbacks. Unless
+// SetCallbackLimits was called, these are read from the JSII_CALLBACK_MAX_DEPTH
+// and JSII_CALLBACK_TIMEOUT environment variables, with invalid values being
+// ignored.
+func GetCallbackLimits() CallbackLimits {
+	callbackLimitsOnce.Do(func() {
+		limits := CallbackLimits{MaxDepth: DefaultCallbackMaxDepth}
+		if depth, err := strconv.Atoi(os.Getenv(JSII_CALLBACK_MAX_DEPTH)); err == nil {
+			limits.MaxDepth = depth
+		}
+		if timeout, err := time.ParseDuration(os.Getenv(JSII_CALLBACK_TIMEOUT)); err == nil {
+			limits.Timeout = timeout
+		}
+		callbackLimits = limits
+	})
+
+	callbackLimitsLock.RLock()
+	defer callbackLimitsLock.RUnlock()
+	return callbackLimits
+}
+
+// SetCallbackLimits changes the limits applied to callbacks, and returns the
+// previous ones. The change applies to callbacks received afterwards.
+func SetCallbackLimits(limits CallbackLimits) (previous CallbackLimits) {
+	previous = GetCallbackLimits()
+
+	callbackLimitsLock.Lock()
+	defer callbackLimitsLock.Unlock()
+	callbackLimits = limits
+	return
+}
+
+// CallbackFrame describes a callback from the kernel that was pending when a
+// callback limit was exceeded.
+type CallbackFrame struct {
+	// CallbackID is the kernel-assigned identifier of the callback.
+	CallbackID string
+	// Kind is "invoke", "get" or "set".
+	Kind string
+	// InstanceID identifies the object the callback targets.
+	InstanceID string
+	// FQN is the jsii type of the object the callback targets, if known.
+	FQN api.FQN
+	// Member is the jsii name of the method or property the callback is for.
+	Member string
+	// Cookie is the go name of the method or property the callback is for.
+	Cookie string
+}
+
+func (f CallbackFrame) String() string {
+	target := f.InstanceID
+	if f.FQN != "" {
+		target = string(f.FQN)
+	}
+	return fmt.Sprintf("%v %v.%v => go %v (instance %v, %v)", f.Kind, target, f.Member, f.Cookie, f.InstanceID, f.CallbackID)
+}
+
+// frame describes this callback as a CallbackFrame.
+func (c *callback) frame() CallbackFrame {
+	frame := CallbackFrame{CallbackID: c.CallbackID, Cookie: c.Cookie}
+
+	var objref api.ObjectRef
+	switch {
+	case c.Invoke != nil:
+		frame.Kind, frame.Member, objref = "invoke", c.Invoke.Method, c.Invoke.ObjRef
+	case c.Get != nil:
+		frame.Kind, frame.Member, objref = "get", c.Get.Property, c.Get.ObjRef
+	case c.Set != nil:
+		frame.Kind, frame.Member, objref = "set", c.Set.Property, c.Set.ObjRef
+	}
+	frame.InstanceID = objref.InstanceID
+	frame.FQN, _ = objref.TryTypeFQN()
+
+	return frame
+}
+
+// formatChain renders the chain of pending callbacks, innermost first, as is
+// customary for stack traces.
+func formatChain(chain []CallbackFrame) string {
+	var lines strings.Builder
+	lines.WriteString("pending callbacks (innermost first):")
+	for i := len(chain) - 1; i >= 0; i-- {
+		fmt.Fprintf(&lines, "\n\t%d. %v", i+1, chain[i])
+	}
+	return lines.String()
+}
+
+// CallbackDepthError is reported when a callback would exceed the maximum
+// depth of nested callbacks, which typically indicates that JavaScript and go
+// code are endlessly recursing into each other.
+type CallbackDepthError struct {
+	// MaxDepth is the limit that was exceeded.
+	MaxDepth int
+	// Chain lists the pending callbacks, outermost first.
+	Chain []CallbackFrame
+}
+
+func (e *CallbackDepthError) Error() string {
+	return fmt.Sprintf("callback depth limit of %d exceeded, JavaScript and go code are likely recursing into each other; %v", e.MaxDepth, formatChain(e.Chain))
+}
+
+// CallbackTimeoutError is reported when go code fulfilling a callback did not
+// complete within the callback timeout, which typically indicates that it is
+// blocked, or deadlocked (e.g: waiting on a Future from within an override).
+type CallbackTimeoutError struct {
+	// Timeout is the limit that was exceeded.
+	Timeout time.Duration
+	// Chain lists the pending callbacks, outermost first.
+	Chain []CallbackFrame
+}
+
+func (e *CallbackTimeoutError) Error() string {
+	return fmt.Sprintf("callback did not complete within %v, the go code fulfilling it may be blocked or deadlocked; %v", e.Timeout, formatChain(e.Chain))
+}
+
+// fulfill executes the provided callback within the current CallbackLimits.
+// The chain lists all pending callbacks, outermost first, ending with this one.
+func (c *Client) fulfill(cb *callback, chain []CallbackFrame) (callbackResult, *GoError) {
+	limits := GetCallbackLimits()
+
+	if limits.MaxDepth > 0 && len(chain) > limits.MaxDepth {
+		return cb.fail(&CallbackDepthError{MaxDepth: limits.MaxDepth, Chain: chain})
+	}
+	if limits.Timeout <= 0 {
+		return cb.handle(c)
+	}
+
+	type outcome struct {
+		result callbackResult
+		goErr  *GoError
+	}
+	// Buffered, so the goroutine does not leak if the callback times out.
+	done := make(chan outcome, 1)
+	goroutine := make(chan uint64, 1)
+	go func() {
+		id := goroutineID()
+		goroutine <- id
+		result, goErr := cb.handle(c)
+		done <- outcome{result, goErr}
+		c.abandoned.forget(id)
+	}()
+
+	timer := time.NewTimer(limits.Timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.result, res.goErr
+	case <-timer.C:
+		timeoutErr := &CallbackTimeoutError{Timeout: limits.Timeout, Chain: chain}
+		id := <-goroutine
+		c.abandoned.add(id, timeoutErr)
+		// The callback may have completed since it timed out, in which case it
+		// may have been forgotten already.
+		select {
+		case <-done:
+			c.abandoned.forget(id)
+		default:
+		}
+		return cb.fail(timeoutErr)
+	}
+}
+
+// abandonedCallbacks tracks the goroutines fulfilling callbacks that timed out.
+// These keep running, but must not make requests to the kernel anymore, as it
+// considers their callback complete, and would process them out of context.
+// The zero value is ready to use.
+type abandonedCallbacks struct {
+	mutex      sync.Mutex
+	goroutines map[uint64]*CallbackTimeoutError
+}
+
+// add records that the goroutine with the provided ID fulfills a callback that
+// timed out with the provided error.
+func (a *abandonedCallbacks) add(id uint64, err *CallbackTimeoutError) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.goroutines == nil {
+		a.goroutines = make(map[uint64]*CallbackTimeoutError)
+	}
+	a.goroutines[id] = err
+}
+
+// forget records that the goroutine with the provided ID is done fulfilling
+// its callback.
+func (a *abandonedCallbacks) forget(id uint64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.goroutines, id)
+}
+
+// check returns an error if the current goroutine fulfills a callback that
+// timed out.
+func (a *abandonedCallbacks) check() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.goroutines) == 0 {
+		return nil
+	}
+	if err, abandoned := a.goroutines[goroutineID()]; abandoned {
+		return fmt.Errorf("the callback was abandoned, so it can no longer make requests to the kernel: %w", err)
+	}
+	return nil
+}
+
+// goroutineID returns the ID of the current goroutine, as reported in the
+// header of its stack trace (e.g: "goroutine 42 [running]:").
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// fail returns the outcome of this callback failing with the provided error.
+func (c *callback) fail(err error) (callbackResult, *GoError) {
+	goErr := newGoError(err, false)
+	goErr.callbackID = c.CallbackID
+	return callbackResult{CallbackID: c.CallbackID, Error: goErr.message()}, goErr
+}
@@ -0,0 +1,132 @@
+package kernel
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+type recursiveReceiver struct {
+	client  *Client
+	objref  api.ObjectRef
+	release chan struct{}
+	late    chan error
+}
+
+// Recurse calls itself through the kernel, forever.
+func (r *recursiveReceiver) Recurse() {
+	if _, err := r.client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{r.objref, "Recurse"}}); err != nil {
+		panic(err)
+	}
+}
+
+// Block waits until released.
+func (r *recursiveReceiver) Block() {
+	<-r.release
+}
+
+// BlockThenEcho waits until released, then makes a request to the kernel, and
+// a pipelined batch of requests, the errors of which are sent to late.
+func (r *recursiveReceiver) BlockThenEcho() {
+	<-r.release
+	_, err := r.client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "echo"})
+	r.late <- err
+	_, err = r.client.GetAll(api.ObjectRef{InstanceID: "test.Level@1"}, []string{"field0", "field1"})
+	r.late <- err
+}
+
+func withCallbackLimits(limits CallbackLimits, cb func()) {
+	previous := SetCallbackLimits(limits)
+	defer SetCallbackLimits(previous)
+	cb()
+}
+
+func TestCallbackDepthLimit(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		receiver := &recursiveReceiver{client: client, objref: api.ObjectRef{InstanceID: "test.Receiver@1"}}
+		if err := client.RegisterInstance(reflect.ValueOf(receiver), receiver.objref); err != nil {
+			t.Fatal(err)
+		}
+
+		withCallbackLimits(CallbackLimits{MaxDepth: 5}, func() {
+			_, err := client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{receiver.objref, "Recurse"}})
+
+			var depthErr *CallbackDepthError
+			if !errors.As(err, &depthErr) {
+				t.Fatalf("expected a *CallbackDepthError cause, got %v", err)
+			}
+			if depthErr.MaxDepth != 5 || len(depthErr.Chain) != 6 {
+				t.Errorf("expected a chain of 6 callbacks for a limit of 5, got %v", depthErr)
+			}
+			frame := depthErr.Chain[0]
+			if frame.Kind != "invoke" || frame.FQN != "test.Receiver" || frame.Member != "Recurse" || frame.Cookie != "Recurse" {
+				t.Errorf("unexpected frame: %#v", frame)
+			}
+			if msg := depthErr.Error(); !strings.Contains(msg, "callback depth limit of 5 exceeded") || !strings.Contains(msg, "6. invoke test.Receiver.Recurse => go Recurse") {
+				t.Errorf("unexpected message: %v", msg)
+			}
+		})
+	})
+}
+
+func TestCallbackTimeout(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		receiver := &recursiveReceiver{client: client, objref: api.ObjectRef{InstanceID: "test.Receiver@1"}, release: make(chan struct{})}
+		defer close(receiver.release)
+		if err := client.RegisterInstance(reflect.ValueOf(receiver), receiver.objref); err != nil {
+			t.Fatal(err)
+		}
+
+		withCallbackLimits(CallbackLimits{Timeout: 50 * time.Millisecond}, func() {
+			_, err := client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{receiver.objref, "Block"}})
+
+			var timeoutErr *CallbackTimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("expected a *CallbackTimeoutError cause, got %v", err)
+			}
+			if timeoutErr.Timeout != 50*time.Millisecond || len(timeoutErr.Chain) != 1 || timeoutErr.Chain[0].Member != "Block" {
+				t.Errorf("unexpected error: %v", timeoutErr)
+			}
+
+			// The kernel remains usable afterwards
+			if _, err := client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "echo"}); err != nil {
+				t.Error(err)
+			}
+		})
+	})
+}
+
+func TestCallbackTimeoutAbandonsCallback(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		receiver := &recursiveReceiver{client: client, objref: api.ObjectRef{InstanceID: "test.Receiver@1"}, release: make(chan struct{}), late: make(chan error)}
+		if err := client.RegisterInstance(reflect.ValueOf(receiver), receiver.objref); err != nil {
+			t.Fatal(err)
+		}
+
+		withCallbackLimits(CallbackLimits{Timeout: 50 * time.Millisecond}, func() {
+			if _, err := client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "callback", Arguments: []interface{}{receiver.objref, "BlockThenEcho"}}); err == nil {
+				t.Fatal("expected the callback to time out")
+			}
+		})
+
+		// The override resumes after it timed out, and its request is rejected...
+		close(receiver.release)
+		for _, kind := range []string{"request", "pipelined batch"} {
+			var timeoutErr *CallbackTimeoutError
+			if err := <-receiver.late; !errors.As(err, &timeoutErr) || !strings.Contains(err.Error(), "the callback was abandoned") {
+				t.Errorf("expected the %v to be rejected, got %v", kind, err)
+			} else if timeoutErr.Chain[0].Member != "BlockThenEcho" {
+				t.Errorf("unexpected error: %v", timeoutErr)
+			}
+		}
+
+		// ... while other goroutines are not affected.
+		if _, err := client.Invoke(InvokeProps{ObjRef: fakeKernelRef, Method: "echo"}); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -5,32 +5,46 @@ import (
 	"io/ioutil"
 	"os"
 	"regexp"
+
+	"github.com/aws/jsii-runtime-go/protocol"
 )
 
 // LoadProps holds the necessary information to load a library into the
-// @jsii/kernel process through the Load method.
-type LoadProps struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
-}
+// @jsii/kernel process through the Load method. The Tarball is provided by the
+// Load method, and must not be set.
+type LoadProps = protocol.LoadProps
 
 // LoadResponse contains the data returned by the @jsii/kernel process in
 // response to a load request.
-type LoadResponse struct {
-	kernelResponse
-	Assembly string  `json:"assembly"`
-	Types    float64 `json:"types"`
+type LoadResponse = protocol.LoadResponse
+
+// loadedAssembly is an assembly that was loaded into the @jsii/kernel process,
+// which is loaded again if the process is recycled.
+type loadedAssembly struct {
+	props   LoadProps
+	tarball []byte
 }
 
 // Load ensures the specified assembly has been loaded into the @jsii/kernel
 // process. This call is idempotent (calling it several times with the same
 // input results in the same output).
 func (c *Client) Load(props LoadProps, tarball []byte) (response LoadResponse, err error) {
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+
+	return c.load(props, tarball)
+}
+
+// load implements Load. The caller must have acquired the conversation with the
+// kernel, so the process is not recycled while the tarball is being loaded.
+func (c *Client) load(props LoadProps, tarball []byte) (response LoadResponse, err error) {
 	if response, cached := c.loaded[props]; cached {
 		return response, nil
 	}
 
-	tmpfile, err := ioutil.TempFile("", fmt.Sprintf(
+	// The tarball is written in the process' temporary directory, so that it is
+	// cleaned up with it if this program is terminated while it is loading.
+	tmpfile, err := ioutil.TempFile(c.process.TempDir(), fmt.Sprintf(
 		"%v-%v.*.tgz",
 		regexp.MustCompile("[^a-zA-Z0-9_-]").ReplaceAllString(props.Name, "-"),
 		version,
@@ -44,23 +58,16 @@ func (c *Client) Load(props LoadProps, tarball []byte) (response LoadResponse, e
 	}
 	tmpfile.Close()
 
-	type request struct {
-		kernelRequest
-		LoadProps
-		Tarball string `json:"tarball"`
-	}
-	err = c.request(request{kernelRequest{"load"}, props, tmpfile.Name()}, &response)
+	request := props
+	request.Tarball = tmpfile.Name()
+	err = c.send(protocol.NewRequest("load", request), &response)
 
 	if err == nil {
 		c.loaded[props] = response
+		// The tarball is typically embedded in the program, so retaining it is
+		// free.
+		c.assemblies = append(c.assemblies, loadedAssembly{props, tarball})
 	}
 
 	return
 }
-
-// UnmarshalJSON provides custom unmarshalling implementation for response
-// structs. Creating new types is required in order to avoid infinite recursion.
-func (r *LoadResponse) UnmarshalJSON(data []byte) error {
-	type response LoadResponse
-	return unmarshalKernelResponse(data, (*response)(r), r)
-}
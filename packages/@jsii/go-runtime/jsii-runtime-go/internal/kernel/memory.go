@@ -0,0 +1,69 @@
+package kernel
+
+import "github.com/aws/jsii-runtime-go/protocol"
+
+// MemoryUsage describes the memory used by the @jsii/kernel process. All sizes
+// are in bytes.
+type MemoryUsage struct {
+	// RSS is the resident set size of the process. It is read from /proc on
+	// Linux, and reported by the kernel otherwise (0 if it does not).
+	RSS uint64
+	// HeapUsed is the size of the node heap in use, as reported by the kernel
+	// (0 if it does not).
+	HeapUsed uint64
+	// HeapTotal is the size of the node heap, as reported by the kernel (0 if
+	// it does not).
+	HeapTotal uint64
+	// External is the size of the memory used by node outside of the heap
+	// (e.g: buffers), as reported by the kernel (0 if it does not).
+	External uint64
+	// KernelObjects is the number of objects tracked by the kernel.
+	KernelObjects int
+	// References is the number of kernel objects registered on the go side,
+	// whether or not they are still referenced (they are only forgotten when
+	// the process is recycled).
+	References int
+	// Recycles is the number of times the process was recycled.
+	Recycles int
+}
+
+// MemoryUsage samples the memory used by the @jsii/kernel process, starting it
+// if that has not happened yet. Heap figures are only available if the kernel
+// supports the stats feature.
+func (c *Client) MemoryUsage() (usage MemoryUsage, err error) {
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+
+	if err = c.negotiate(); err != nil {
+		return
+	}
+	return c.sampleMemory()
+}
+
+// sampleMemory samples the memory used by the @jsii/kernel process, which must
+// have been started. The caller must have acquired the conversation with the
+// kernel.
+func (c *Client) sampleMemory() (usage MemoryUsage, err error) {
+	usage.References = c.objects.Count()
+	usage.Recycles = c.recycles
+
+	if c.wire.Features().Has(protocol.FeatureStats) {
+		var stats StatsResponse
+		if err = c.send(protocol.NewRequest("stats", nil), &stats); err != nil {
+			return
+		}
+		usage.KernelObjects = int(stats.ObjectCount)
+		if memory := stats.Memory; memory != nil {
+			usage.RSS = uint64(memory.RSS)
+			usage.HeapUsed = uint64(memory.HeapUsed)
+			usage.HeapTotal = uint64(memory.HeapTotal)
+			usage.External = uint64(memory.External)
+		}
+	}
+
+	// The kernel's own figure is used where /proc is not available.
+	if rss, err := c.process.ResidentMemory(); err == nil {
+		usage.RSS = rss
+	}
+	return
+}
@@ -1,21 +1,13 @@
 package kernel
 
-type NamingProps struct {
-	Assembly string `json:"assembly"`
-}
+import "github.com/aws/jsii-runtime-go/protocol"
 
-type NamingResponse struct {
-	kernelResponse
-	// readonly naming: {
-	//   readonly [language: string]: { readonly [key: string]: any } | undefined;
-	// };
-}
+type (
+	NamingProps    = protocol.NamingProps
+	NamingResponse = protocol.NamingResponse
+)
 
 func (c *Client) Naming(props NamingProps) (response NamingResponse, err error) {
-	type request struct {
-		kernelRequest
-		NamingProps
-	}
-	err = c.request(request{kernelRequest{"naming"}, props}, &response)
+	err = c.request(protocol.NewRequest("naming", props), &response)
 	return
 }
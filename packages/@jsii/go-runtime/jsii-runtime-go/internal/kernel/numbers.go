@@ -0,0 +1,171 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxSafeInteger is the largest integer n such that n and n+1 can both be
+// represented exactly as a JavaScript number (Number.MAX_SAFE_INTEGER).
+const maxSafeInteger = 1<<53 - 1
+
+var numberType = reflect.TypeOf(json.Number(""))
+
+// castValToNumber converts the provided wire value (a json.Number, or any
+// floating-point value) into a value of the numeric type typ. Integer types
+// are only accepted if the value is integral, and within the range of typ.
+// Numbers are converted to float64 when typ is interface{}, unless they cannot
+// be represented exactly (see genericNumber). The returned boolean is false if
+// the data is not a number, or typ is not numeric.
+func castValToNumber(data reflect.Value, typ reflect.Type) (result reflect.Value, ok bool, err error) {
+	if data.Type() != numberType && data.Kind() != reflect.Float32 && data.Kind() != reflect.Float64 {
+		return
+	}
+	if typ == anyType && data.Type() == numberType {
+		return reflect.ValueOf(genericNumber(json.Number(data.String()))), true, nil
+	}
+
+	target := typ
+	if typ == anyType {
+		target = reflect.TypeOf(float64(0))
+	}
+	result = reflect.New(target).Elem()
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ok = true
+		var i int64
+		if i, err = intValue(data); err != nil {
+			return
+		}
+		if result.OverflowInt(i) {
+			err = fmt.Errorf("number %v is out of range for %v", i, typ)
+			return
+		}
+		result.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		ok = true
+		var u uint64
+		if u, err = uintValue(data); err != nil {
+			return
+		}
+		if result.OverflowUint(u) {
+			err = fmt.Errorf("number %v is out of range for %v", u, typ)
+			return
+		}
+		result.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		ok = true
+		var f float64
+		if f, err = floatValue(data); err != nil {
+			return
+		}
+		if result.OverflowFloat(f) {
+			err = fmt.Errorf("number %v is out of range for %v", f, typ)
+			return
+		}
+		result.SetFloat(f)
+	}
+
+	return
+}
+
+// genericNumber converts the provided json.Number into a float64, unless it is
+// an integer that float64 cannot represent exactly (i.e: it would be rounded),
+// in which case it is returned unchanged, so no precision is silently lost.
+func genericNumber(n json.Number) interface{} {
+	f, err := n.Float64()
+	if err != nil {
+		return n
+	}
+	if text := n.String(); !strings.ContainsAny(text, ".eE") && strconv.FormatFloat(f, 'f', -1, 64) != strings.TrimPrefix(text, "+") {
+		return n
+	}
+	return f
+}
+
+// intValue obtains the value of the provided wire number as an int64. When the
+// number is a json.Number, its textual representation is parsed directly, so
+// no precision is lost. Returns an error if the number is not integral, or is
+// out of range.
+func intValue(data reflect.Value) (int64, error) {
+	if data.Type() == numberType {
+		if i, err := strconv.ParseInt(data.String(), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+
+	f, err := integralValue(data)
+	if err != nil {
+		return 0, err
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("number %v is out of range for int64", f)
+	}
+	return int64(f), nil
+}
+
+// uintValue obtains the value of the provided wire number as an uint64. When
+// the number is a json.Number, its textual representation is parsed directly,
+// so no precision is lost. Returns an error if the number is not integral, or
+// is out of range.
+func uintValue(data reflect.Value) (uint64, error) {
+	if data.Type() == numberType {
+		if u, err := strconv.ParseUint(data.String(), 10, 64); err == nil {
+			return u, nil
+		}
+	}
+
+	f, err := integralValue(data)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 || f >= math.MaxUint64 {
+		return 0, fmt.Errorf("number %v is out of range for uint64", f)
+	}
+	return uint64(f), nil
+}
+
+// integralValue obtains the value of the provided wire number as a float64,
+// and returns an error if it is not an integer.
+func integralValue(data reflect.Value) (float64, error) {
+	f, err := floatValue(data)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("number %v is not an integer", f)
+	}
+	return f, nil
+}
+
+// floatValue obtains the value of the provided wire number as a float64.
+func floatValue(data reflect.Value) (float64, error) {
+	if data.Type() == numberType {
+		return strconv.ParseFloat(data.String(), 64)
+	}
+	return data.Float(), nil
+}
+
+// castIntegerToNumber validates that the provided integer value can be
+// represented exactly as a JavaScript number, and returns it unchanged.
+// Returns an error otherwise, as the value would be silently rounded.
+func castIntegerToNumber(data reflect.Value) (interface{}, error) {
+	switch data.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := data.Int(); i > maxSafeInteger || i < -maxSafeInteger {
+			return nil, fmt.Errorf("integer %v cannot be represented exactly as a JavaScript number", i)
+		}
+	default:
+		if u := data.Uint(); u > maxSafeInteger {
+			return nil, fmt.Errorf("integer %v cannot be represented exactly as a JavaScript number", u)
+		}
+	}
+	return data.Interface(), nil
+}
@@ -0,0 +1,52 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// live tracks the processes that have not been closed yet, so they can be
+	// cleaned up by TerminateAll.
+	live      = make(map[*Process]struct{})
+	liveMutex sync.Mutex
+)
+
+func register(p *Process) {
+	liveMutex.Lock()
+	defer liveMutex.Unlock()
+	live[p] = struct{}{}
+}
+
+func unregister(p *Process) {
+	liveMutex.Lock()
+	defer liveMutex.Unlock()
+	delete(live, p)
+}
+
+// TerminateAll closes all processes that have not been closed yet, giving each
+// of them up to the provided timeout to exit gracefully before escalating (see
+// CloseWithTimeout). It returns the number of processes that did not exit
+// gracefully.
+func TerminateAll(timeout time.Duration) (forced int) {
+	liveMutex.Lock()
+	processes := make([]*Process, 0, len(live))
+	for p := range live {
+		processes = append(processes, p)
+	}
+	liveMutex.Unlock()
+
+	stages := make(chan CloseStage, len(processes))
+	for _, p := range processes {
+		go func(p *Process) {
+			stage, _ := p.CloseWithTimeout(timeout)
+			stages <- stage
+		}(p)
+	}
+	for range processes {
+		if stage := <-stages; stage > CloseStageExit {
+			forced++
+		}
+	}
+	return
+}
@@ -0,0 +1,229 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+var (
+	// terminateTimeout is how long the child process has to exit once it has
+	// been sent SIGTERM, before it is killed.
+	terminateTimeout = 3 * time.Second
+	// killTimeout is how long the child process has to exit once it has been
+	// killed, before closing it fails.
+	killTimeout = 3 * time.Second
+	// stderrDrainTimeout is how long closing waits for the child process'
+	// stderr to be consumed, as it may be held open by processes it spawned.
+	stderrDrainTimeout = time.Second
+)
+
+// CloseStage identifies the step of closing a process that caused the child
+// process to exit.
+type CloseStage int
+
+const (
+	// CloseStageNone means the child process was not running.
+	CloseStageNone CloseStage = iota
+	// CloseStageExit means the child process exited gracefully once it was
+	// sent the exit message.
+	CloseStageExit
+	// CloseStageTerminate means the child process exited once it was sent
+	// SIGTERM (which is not supported on Windows).
+	CloseStageTerminate
+	// CloseStageKill means the child process had to be killed.
+	CloseStageKill
+)
+
+func (s CloseStage) String() string {
+	switch s {
+	case CloseStageNone:
+		return "none"
+	case CloseStageExit:
+		return "exit message"
+	case CloseStageTerminate:
+		return "SIGTERM"
+	case CloseStageKill:
+		return "SIGKILL"
+	default:
+		return fmt.Sprintf("CloseStage(%d)", int(s))
+	}
+}
+
+// Close sends the exit message to the child process, and waits for it to exit,
+// however long that takes. It then releases all resources associated with the
+// process, including its temporary directory.
+func (p *Process) Close() {
+	p.CloseContext(context.Background())
+}
+
+// CloseWithTimeout is like CloseContext, giving the child process up to the
+// provided timeout to exit gracefully.
+func (p *Process) CloseWithTimeout(timeout time.Duration) (CloseStage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return p.CloseContext(ctx)
+}
+
+// CloseContext sends the exit message to the child process, and waits for it to
+// exit until the context is done. It then escalates to sending SIGTERM, and
+// finally to killing the child process, waiting a few seconds for it to exit
+// after each. It returns the stage that caused the child process to exit,
+// and an error if it did not exit at all. All resources associated with the
+// process, including its temporary directory, are released in any case.
+//
+// It is safe to call concurrently with requests, which fail once the process is
+// closed, and with other calls to close the process, which wait for the first
+// one to complete and return the same outcome.
+func (p *Process) CloseContext(ctx context.Context) (CloseStage, error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		<-p.closeDone
+		return p.closeStage, p.closeErr
+	}
+	p.closed = true
+	started := p.started
+	p.mutex.Unlock()
+
+	defer close(p.closeDone)
+	if started {
+		p.closeStage, p.closeErr = p.shutdown(ctx)
+	}
+	p.release()
+	unregister(p)
+
+	return p.closeStage, p.closeErr
+}
+
+// shutdown escalates from the exit message, to SIGTERM, to SIGKILL until the
+// child process exits.
+func (p *Process) shutdown(ctx context.Context) (CloseStage, error) {
+	select {
+	case <-p.exited:
+		return CloseStageNone, nil
+	default:
+	}
+
+	// The exit message waits for requests being sent to be fully written, which
+	// may not happen if the child process no longer reads them, so this does not
+	// hold up escalating. Ignoring errors, as the child process may have exited
+	// already.
+	go func() {
+		p.sendMutex.Lock()
+		defer p.sendMutex.Unlock()
+
+		p.conn.Send(protocol.Exit{Code: 0})
+		p.stdin.Close()
+	}()
+
+	select {
+	case <-p.exited:
+		return CloseStageExit, nil
+	case <-ctx.Done():
+	}
+
+	if err := p.child.Signal(syscall.SIGTERM); err == nil {
+		if p.waitExited(terminateTimeout) {
+			return CloseStageTerminate, nil
+		}
+	}
+
+	p.child.Kill()
+	if p.waitExited(killTimeout) {
+		return CloseStageKill, nil
+	}
+	return CloseStageKill, fmt.Errorf("the child process (PID %d) did not exit after being killed", p.child.Pid)
+}
+
+// waitExited waits up to timeout for the child process to exit, and returns
+// whether it did.
+func (p *Process) waitExited(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-p.exited:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// release closes the pipes to the child process, once it is no longer running,
+// and removes the temporary directory.
+func (p *Process) release() {
+	// Ignoring errors, as the pipes may have been closed already. They are not
+	// set if the process could not be prepared.
+	if p.stdin != nil {
+		p.stdin.Close()
+	}
+	if p.stdout != nil {
+		p.stdout.Close()
+	}
+
+	if p.stderrDone != nil {
+		// Let the stderr sink goroutine consume what is left, unless processes
+		// spawned by the child process hold the stream open.
+		timer := time.NewTimer(stderrDrainTimeout)
+		select {
+		case <-p.stderrDone:
+		case <-timer.C:
+			p.stderr.Close()
+			<-p.stderrDone
+		}
+		timer.Stop()
+	}
+	if p.stderr != nil {
+		p.stderr.Close()
+	}
+
+	if p.tmpdir != "" {
+		if err := os.RemoveAll(p.tmpdir); err != nil {
+			fmt.Fprintf(os.Stderr, "could not clean up temporary directory: %v\n", err)
+		}
+	}
+}
+
+// reap waits for the child process to exit, and closes the exited channel when
+// it does. It is the only caller of Wait, which (unlike exec.Cmd.Wait) leaves
+// the pipes open, so their contents can be consumed. If the child process
+// exits on its own once it is running, the process is closed. Failures to
+// start are handled by ensureStarted instead.
+func (p *Process) reap() {
+	state, err := p.child.Wait()
+	close(p.exited)
+
+	p.mutex.Lock()
+	closed, running := p.closed, p.running
+	p.mutex.Unlock()
+	if closed || !running {
+		return
+	}
+
+	if err == nil && !state.Success() {
+		err = fmt.Errorf("%v", state)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Runtime process exited abnormally: %v\n", err)
+	}
+	p.Close()
+}
+
+// Kill forcibly terminates the child process, if it was started, and removes
+// the temporary directory. Unlike Close, it does not wait for anything, and it
+// is safe to call at any time, including while Close is blocked. Close must
+// still be called to release the remaining resources.
+func (p *Process) Kill() {
+	p.mutex.Lock()
+	if p.child != nil {
+		// Ignoring errors, as the process may have exited already.
+		p.child.Kill()
+	}
+	p.mutex.Unlock()
+
+	os.RemoveAll(p.tmpdir)
+}
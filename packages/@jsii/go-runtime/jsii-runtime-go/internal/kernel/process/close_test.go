@@ -0,0 +1,98 @@
+package process
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// withShortTimeouts shortens the escalation timeouts for the duration of a test.
+func withShortTimeouts(t *testing.T) {
+	oldTerminate, oldKill := terminateTimeout, killTimeout
+	terminateTimeout, killTimeout = 500*time.Millisecond, 5*time.Second
+	t.Cleanup(func() { terminateTimeout, killTimeout = oldTerminate, oldKill })
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+	withShortTimeouts(t)
+
+	cases := map[string]CloseStage{"graceful": CloseStageExit, "stubborn": CloseStageTerminate, "wedged": CloseStageKill}
+	if runtime.GOOS == "windows" {
+		// SIGTERM is not supported on Windows.
+		cases["stubborn"] = CloseStageKill
+	}
+
+	for mode, expected := range cases {
+		t.Run(mode, func(t *testing.T) {
+			oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+			if runtime, err := makeCustomRuntime("4.3.2 " + mode); err != nil {
+				t.Fatal(err)
+			} else {
+				os.Setenv(JSII_RUNTIME, runtime)
+			}
+			defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+			process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{}); err != nil {
+				t.Fatal(err)
+			}
+
+			stage, err := process.CloseWithTimeout(500 * time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stage != expected {
+				t.Errorf("expected the process to exit at stage %v, got %v", expected, stage)
+			}
+			if _, err := os.Stat(process.TempDir()); !os.IsNotExist(err) {
+				t.Errorf("expected the temporary directory to be removed, got %v", err)
+			}
+
+			// Closing again reports the same outcome.
+			if again, err := process.CloseWithTimeout(0); again != stage || err != nil {
+				t.Errorf("expected %v again, got %v (%v)", stage, again, err)
+			}
+		})
+	}
+}
+
+func TestCloseDuringRequest(t *testing.T) {
+	withShortTimeouts(t)
+
+	oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+	if runtime, err := makeCustomRuntime("4.3.2 wedged"); err != nil {
+		t.Fatal(err)
+	} else {
+		os.Setenv(JSII_RUNTIME, runtime)
+	}
+	defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := make(chan error, 1)
+	go func() {
+		failed <- process.Request(EchoRequest{Message: "hang"}, &EchoResponse{})
+	}()
+
+	if stage, err := process.CloseWithTimeout(100 * time.Millisecond); err != nil || stage != CloseStageKill {
+		t.Errorf("expected the process to be killed, got %v (%v)", stage, err)
+	}
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Error("expected the in-flight request to fail")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the in-flight request did not complete")
+	}
+}
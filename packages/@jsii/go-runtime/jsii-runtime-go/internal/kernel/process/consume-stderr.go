@@ -17,7 +17,8 @@ type consoleMessage struct {
 // and will decode any console messages per the jsii wire protocol specification.
 // Once EOF has been reached, true will be sent to the done channel, allowing
 // other goroutines to check whether the goroutine has reached EOF (and hence
-// finished) or not.
+// finished) or not. The last few kilobytes written to stderr are retained, so
+// they can be reported if the process fails to start.
 func (p *Process) consumeStderr(done chan bool) {
 	reader := bufio.NewReader(p.stderr)
 
@@ -30,9 +31,11 @@ func (p *Process) consumeStderr(done chan bool) {
 		var message consoleMessage
 		if err := json.Unmarshal(line, &message); err != nil {
 			os.Stderr.Write(line)
+			p.stderrTail.Write(line)
 		} else {
 			if message.Stderr != nil {
 				os.Stderr.Write(message.Stderr)
+				p.stderrTail.Write(message.Stderr)
 			}
 			if message.Stdout != nil {
 				os.Stdout.Write(message.Stdout)
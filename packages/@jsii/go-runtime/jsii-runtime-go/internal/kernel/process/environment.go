@@ -0,0 +1,133 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	// JSII_ENV_POLICY is the name of the environment variable that sets the
+	// default EnvironmentPolicy.Clean: "clean" forwards only the minimal
+	// environment to the child process, "inherit" (the default) forwards all
+	// of it.
+	JSII_ENV_POLICY = "JSII_ENV_POLICY"
+	// JSII_ENV_ALLOW is the name of the environment variable that sets the
+	// default EnvironmentPolicy.Allow, as a comma-separated list of patterns.
+	JSII_ENV_ALLOW = "JSII_ENV_ALLOW"
+	// JSII_ENV_DENY is the name of the environment variable that sets the
+	// default EnvironmentPolicy.Deny, as a comma-separated list of patterns.
+	JSII_ENV_DENY = "JSII_ENV_DENY"
+)
+
+// MinimalEnvironment lists the patterns of the variables forwarded to the child
+// process in clean mode: those node and the operating system need to function
+// correctly, and those configuring jsii itself.
+var MinimalEnvironment = []string{
+	"PATH", "PATHEXT", "HOME", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+	"TMPDIR", "TMP", "TEMP", "SYSTEMROOT", "WINDIR", "COMSPEC",
+	"LANG", "LC_*", "TZ", "TERM",
+	"NODE_OPTIONS", "JSII_*",
+}
+
+// EnvironmentPolicy controls which environment variables of the current process
+// are forwarded to the child process. Patterns use the path.Match syntax (e.g:
+// "AWS_*"), and are case-insensitive on Windows.
+//
+// If neither Clean nor Allow are set, all variables are forwarded. Otherwise,
+// only the variables matching Allow are forwarded, in addition to those in
+// MinimalEnvironment if Clean is set. Variables matching Deny are never
+// forwarded. Extra variables are always added, and JSII_AGENT is always set by
+// the runtime.
+type EnvironmentPolicy struct {
+	// Clean forwards only the variables matching MinimalEnvironment or Allow.
+	Clean bool
+	// Allow lists patterns of variables to forward.
+	Allow []string
+	// Deny lists patterns of variables not to forward, even if allowed.
+	Deny []string
+	// Extra lists variables to set in the child process' environment, which
+	// take precedence over forwarded variables.
+	Extra map[string]string
+}
+
+// DefaultEnvironmentPolicy returns the EnvironmentPolicy described by the
+// JSII_ENV_POLICY, JSII_ENV_ALLOW and JSII_ENV_DENY environment variables.
+func DefaultEnvironmentPolicy() (policy EnvironmentPolicy, err error) {
+	switch mode := os.Getenv(JSII_ENV_POLICY); mode {
+	case "", "inherit":
+	case "clean":
+		policy.Clean = true
+	default:
+		return policy, fmt.Errorf("invalid value for %v: %q (expected \"clean\" or \"inherit\")", JSII_ENV_POLICY, mode)
+	}
+	policy.Allow = splitPatterns(os.Getenv(JSII_ENV_ALLOW))
+	policy.Deny = splitPatterns(os.Getenv(JSII_ENV_DENY))
+	return policy, policy.Validate()
+}
+
+// splitPatterns splits a comma-separated list of patterns, ignoring blanks.
+func splitPatterns(list string) (patterns []string) {
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return
+}
+
+// Validate returns an error if any of the policy's patterns is malformed.
+func (p EnvironmentPolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid environment variable pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Apply returns the environment of the child process, given the environment
+// of the current process (as returned by os.Environ).
+func (p EnvironmentPolicy) Apply(environ []string) []string {
+	forwardAll := !p.Clean && len(p.Allow) == 0
+
+	result := make([]string, 0, len(environ)+len(p.Extra))
+	for _, kv := range environ {
+		name := kv
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			name = kv[:i]
+		}
+		allowed := forwardAll || matchesAny(p.Allow, name) || (p.Clean && matchesAny(MinimalEnvironment, name))
+		if allowed && !matchesAny(p.Deny, name) {
+			result = append(result, kv)
+		}
+	}
+
+	// Sorted, so the resulting environment is deterministic.
+	extra := make([]string, 0, len(p.Extra))
+	for name, value := range p.Extra {
+		extra = append(extra, fmt.Sprintf("%v=%v", name, value))
+	}
+	sort.Strings(extra)
+
+	return append(result, extra...)
+}
+
+// matchesAny returns true if name matches any of the provided patterns.
+func matchesAny(patterns []string, name string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, pattern := range patterns {
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package process
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentPolicy(t *testing.T) {
+	environ := []string{"PATH=/bin", "HOME=/home/user", "AWS_SECRET_ACCESS_KEY=secret", "AWS_REGION=us-east-1", "GITHUB_TOKEN=token", "JSII_DEBUG=1"}
+
+	cases := map[string]struct {
+		policy   EnvironmentPolicy
+		expected []string
+	}{
+		"inherit": {
+			policy:   EnvironmentPolicy{},
+			expected: environ,
+		},
+		"deny": {
+			policy:   EnvironmentPolicy{Deny: []string{"AWS_*", "*_TOKEN"}},
+			expected: []string{"PATH=/bin", "HOME=/home/user", "JSII_DEBUG=1"},
+		},
+		"allow": {
+			policy:   EnvironmentPolicy{Allow: []string{"PATH", "AWS_*"}, Deny: []string{"AWS_SECRET_*"}},
+			expected: []string{"PATH=/bin", "AWS_REGION=us-east-1"},
+		},
+		"clean": {
+			policy:   EnvironmentPolicy{Clean: true, Allow: []string{"AWS_REGION"}},
+			expected: []string{"PATH=/bin", "HOME=/home/user", "AWS_REGION=us-east-1", "JSII_DEBUG=1"},
+		},
+		"extra": {
+			policy:   EnvironmentPolicy{Clean: true, Deny: []string{"HOME"}, Extra: map[string]string{"HOME": "/tmp", "A": "b"}},
+			expected: []string{"PATH=/bin", "JSII_DEBUG=1", "A=b", "HOME=/tmp"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := tc.policy.Apply(environ); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDefaultEnvironmentPolicy(t *testing.T) {
+	for _, name := range []string{JSII_ENV_POLICY, JSII_ENV_ALLOW, JSII_ENV_DENY} {
+		defer os.Setenv(name, os.Getenv(name))
+	}
+
+	os.Setenv(JSII_ENV_POLICY, "clean")
+	os.Setenv(JSII_ENV_ALLOW, "AWS_REGION, CDK_*")
+	os.Setenv(JSII_ENV_DENY, "")
+	if policy, err := DefaultEnvironmentPolicy(); err != nil {
+		t.Fatal(err)
+	} else if expected := (EnvironmentPolicy{Clean: true, Allow: []string{"AWS_REGION", "CDK_*"}}); !reflect.DeepEqual(policy, expected) {
+		t.Errorf("expected %#v, got %#v", expected, policy)
+	}
+
+	os.Setenv(JSII_ENV_POLICY, "paranoid")
+	if _, err := DefaultEnvironmentPolicy(); err == nil || !strings.Contains(err.Error(), JSII_ENV_POLICY) {
+		t.Errorf("expected an invalid policy error, got %v", err)
+	}
+
+	os.Setenv(JSII_ENV_POLICY, "")
+	os.Setenv(JSII_ENV_DENY, "AWS_[")
+	if _, err := DefaultEnvironmentPolicy(); err == nil || !strings.Contains(err.Error(), "AWS_[") {
+		t.Errorf("expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestNewProcessAppliesEnvironmentPolicy(t *testing.T) {
+	defer os.Setenv("JSII_TEST_SECRET", os.Getenv("JSII_TEST_SECRET"))
+	os.Setenv("JSII_TEST_SECRET", "secret")
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{Clean: true, Deny: []string{"JSII_TEST_*", "JSII_AGENT"}, Extra: map[string]string{"JSII_AGENT": "spoofed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer process.Close()
+
+	env := process.cmd.Env
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "JSII_TEST_SECRET=") {
+			t.Errorf("expected JSII_TEST_SECRET not to be forwarded, got %v", env)
+		}
+	}
+	// JSII_AGENT is always controlled by the runtime, and the last value wins.
+	if last := env[len(env)-1]; !strings.HasPrefix(last, "JSII_AGENT=go") {
+		t.Errorf("expected JSII_AGENT to be enforced, got %v", last)
+	}
+}
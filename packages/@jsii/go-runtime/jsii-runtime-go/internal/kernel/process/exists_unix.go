@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processExists determines whether a process with the provided PID is running,
+// by sending it the null signal.
+func processExists(pid int) bool {
+	err := syscall.Kill(pid, syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package process
+
+import "os"
+
+// processExists determines whether a process with the provided PID is running.
+// On Windows, os.FindProcess fails if there is no such process.
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -5,11 +5,32 @@ const process = require('process');
  * provided runtime version number.
  *
  * It response to any request except for "exit" by repeating it
- * back to the parent process. The "exit" handling is "standard".
+ * back to the parent process, unless the request's message is "hang". The
+ * "exit" handling is "standard".
  *
  * @param version the version number to report in the HELLO message.
+ * @param mode     "silent" to never send the HELLO message, "crash" to exit
+ *                 with an error instead of sending it, "stubborn" to keep
+ *                 running after stdin is closed, or "wedged" to also ignore
+ *                 SIGTERM.
  */
-function main(version) {
+function main(version, mode) {
+    if (mode === 'silent') {
+        setInterval(() => {}, 1000);
+        return;
+    }
+    if (mode === 'crash') {
+        console.error(`Error: Cannot find module '@mock/jsii-runtime@${version}'`);
+        process.exit(1);
+    }
+
+    if (mode === 'stubborn' || mode === 'wedged') {
+        setInterval(() => {}, 1000);
+    }
+    if (mode === 'wedged') {
+        process.on('SIGTERM', () => {});
+    }
+
     console.log(JSON.stringify({ hello: `@mock/jsii-runtime@${version}` }));
 
     let buffer = "";
@@ -24,6 +45,8 @@ function main(version) {
             const message = JSON.parse(line);
             if (message.exit) {
                 process.exit(message.exit);
+            } else if (message.message === 'hang') {
+                // Never respond to this one.
             } else {
                 console.log(JSON.stringify(message));
             }
@@ -0,0 +1,17 @@
+package process
+
+import "fmt"
+
+// ResidentMemory returns the resident set size of the child process, in bytes.
+// It returns an error if the child process is not running, or if this is not
+// supported on the current OS (only Linux is).
+func (p *Process) ResidentMemory() (uint64, error) {
+	p.mutex.Lock()
+	child, closed := p.child, p.closed
+	p.mutex.Unlock()
+
+	if child == nil || closed {
+		return 0, fmt.Errorf("the child process is not running")
+	}
+	return residentMemory(child.Pid)
+}
@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// residentMemory reads the resident set size of the process with the provided
+// PID from the VmRSS field of /proc/<pid>/status.
+func residentMemory(pid int) (uint64, error) {
+	status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, err
+	}
+	return parseVmRSS(status)
+}
+
+// parseVmRSS extracts the VmRSS field (e.g: "VmRSS:     1234 kB") from the
+// contents of /proc/<pid>/status, in bytes.
+func parseVmRSS(status []byte) (uint64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "VmRSS:"))
+		if len(fields) != 2 || fields[1] != "kB" {
+			return 0, fmt.Errorf("unexpected VmRSS field: %q", line)
+		}
+		kilobytes, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected VmRSS field: %q", line)
+		}
+		return kilobytes * 1024, nil
+	}
+	return 0, fmt.Errorf("no VmRSS field in the process status")
+}
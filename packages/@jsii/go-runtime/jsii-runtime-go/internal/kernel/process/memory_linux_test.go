@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseVmRSS(t *testing.T) {
+	status := "Name:\tnode\nVmPeak:\t  600000 kB\nVmRSS:\t   51200 kB\nThreads:\t11\n"
+	if rss, err := parseVmRSS([]byte(status)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if rss != 51200*1024 {
+		t.Errorf("expected %d bytes, got %d", 51200*1024, rss)
+	}
+
+	for _, status := range []string{"Name:\tnode\n", "VmRSS:\t12 MB\n", "VmRSS:\tlots kB\n"} {
+		if _, err := parseVmRSS([]byte(status)); err == nil {
+			t.Errorf("expected an error for %q", status)
+		}
+	}
+}
+
+func TestResidentMemory(t *testing.T) {
+	oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+	if runtime, err := makeCustomRuntime("4.3.2"); err != nil {
+		t.Fatal(err)
+	} else {
+		os.Setenv(JSII_RUNTIME, runtime)
+	}
+	defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := process.ResidentMemory(); err == nil {
+		t.Errorf("expected an error before the process is started")
+	}
+
+	if err := process.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if rss, err := process.ResidentMemory(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if rss == 0 {
+		t.Errorf("expected a non-zero resident memory")
+	}
+
+	process.Close()
+	if _, err := process.ResidentMemory(); err == nil {
+		t.Errorf("expected an error once the process is closed")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// residentMemory is not supported on this OS.
+func residentMemory(pid int) (uint64, error) {
+	return 0, fmt.Errorf("the resident memory of processes is not available on %v", runtime.GOOS)
+}
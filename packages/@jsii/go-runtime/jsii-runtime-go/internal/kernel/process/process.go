@@ -1,50 +1,67 @@
 package process
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/aws/jsii-runtime-go/internal/embedded"
+	"github.com/aws/jsii-runtime-go/protocol"
 )
 
 const JSII_RUNTIME string = "JSII_RUNTIME"
 
-type ErrorResponse struct {
-	Error string  `json:"error"`
-	Stack *string `json:"stack"`
-	Name  *string `json:"name"`
-}
-
 // Process is a simple interface over the child process hosting the
 // @jsii/kernel process. It only exposes a very straight-forward
 // request/response interface.
 type Process struct {
 	compatibleVersions *semver.Constraints
 
-	cmd    *exec.Cmd
-	tmpdir string
+	cmd      *exec.Cmd
+	tmpdir   string
+	embedded bool
+
+	// The started child process, and the channel closed once it has exited.
+	child  *os.Process
+	exited chan struct{}
 
 	stdin  io.WriteCloser
 	stdout io.ReadCloser
 	stderr io.ReadCloser
 
-	requests   *json.Encoder
-	responses  *json.Decoder
+	conn       *protocol.Conn
 	stderrDone chan bool
-
-	started bool
-	closed  bool
-
-	mutex sync.Mutex
+	stderrTail tailBuffer
+
+	// Serializes sending messages to the child process, so that the requests
+	// of a pipelined batch, and the exit message, are never interleaved.
+	sendMutex sync.Mutex
+
+	node             *NodeInfo
+	handshakeTimeout time.Duration
+	hello            protocol.Hello
+	entrypoint       string
+
+	// Guards started, running, closed and child. Starting the process is
+	// serialized by startMutex, so that the process may be closed while it
+	// starts. The process is running once the handshake has been received.
+	started    bool
+	running    bool
+	closed     bool
+	mutex      sync.Mutex
+	startMutex sync.Mutex
+
+	// Closed once the process has been closed, with the outcome of closing it.
+	closeDone  chan struct{}
+	closeStage CloseStage
+	closeErr   error
 }
 
 // NewProcess prepares a new child process, but does not start it yet. It will
@@ -54,14 +71,32 @@ type Process struct {
 // If the JSII_RUNTIME environment variable is set, this command will be used
 // to start the child process, in a sub-shell (using %COMSPEC% or cmd.exe on
 // Windows; $SHELL or /bin/sh on other OS'es). Otherwise, the embedded runtime
-// application will be extracted into a temporary directory, and used.
+// application will be extracted into a temporary directory, and used. The
+// temporary directory is created in all cases, as it also holds the library
+// tarballs being loaded, and is removed when the process is closed. Temporary
+// directories left behind by processes that did not close properly (e.g: if the
+// program was killed) are removed by SweepOrphans.
+//
+// The current process' environment is inherited by the child process, as
+// filtered by the provided EnvironmentPolicy. Additional environment may be
+// injected into the child process' environment - all of which with lower
+// precedence than the launching process' environment, with the notable exception
+// of JSII_AGENT, which is reserved.
 //
-// The current process' environment is inherited by the child process. Additional
-// environment may be injected into the child process' environment - all of which
-// with lower precedence than the launching process' environment, with the notable
-// exception of JSII_AGENT, which is reserved.
-func NewProcess(compatibleVersions string) (*Process, error) {
-	p := Process{}
+// The child process must complete its handshake within the duration set by the
+// JSII_HANDSHAKE_TIMEOUT environment variable (DefaultHandshakeTimeout if not
+// set), which can be changed using SetHandshakeTimeout.
+func NewProcess(compatibleVersions string, policy EnvironmentPolicy) (*Process, error) {
+	p := Process{
+		handshakeTimeout: handshakeTimeout(),
+		exited:           make(chan struct{}),
+		closeDone:        make(chan struct{}),
+	}
+	p.stderrTail.size = stderrTailSize
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
 
 	if constraints, err := semver.NewConstraint(compatibleVersions); err != nil {
 		return nil, err
@@ -69,6 +104,13 @@ func NewProcess(compatibleVersions string) (*Process, error) {
 		p.compatibleVersions = constraints
 	}
 
+	sweepOrphansOnce()
+	if tmpdir, err := makeTempDir(); err != nil {
+		return nil, err
+	} else {
+		p.tmpdir = tmpdir
+	}
+
 	if custom := os.Getenv(JSII_RUNTIME); custom != "" {
 		var (
 			command string
@@ -96,22 +138,20 @@ func NewProcess(compatibleVersions string) (*Process, error) {
 			args = []string{"-c", custom}
 		}
 		p.cmd = exec.Command(command, args...)
-	} else if tmpdir, err := ioutil.TempDir("", "jsii-runtime.*"); err != nil {
+		p.entrypoint = custom
+	} else if entrypoint, err := embedded.ExtractRuntime(p.tmpdir); err != nil {
+		p.Close()
 		return nil, err
 	} else {
-		p.tmpdir = tmpdir
-		if entrypoint, err := embedded.ExtractRuntime(tmpdir); err != nil {
-			p.Close()
-			return nil, err
-		} else {
-			p.cmd = exec.Command("node", entrypoint)
-		}
+		p.cmd = exec.Command("node", entrypoint)
+		p.entrypoint = entrypoint
+		p.embedded = true
 	}
 
 	// Setting up environment - if duplicate keys are found, the last value is used, so we are careful with ordering. In
 	// particular, we are setting NODE_OPTIONS only if `os.Environ()` does not have another value... So the user can
 	// control the environment... However, JSII_AGENT must always be controlled by this process.
-	p.cmd.Env = append([]string{"NODE_OPTIONS=--max-old-space-size=4069"}, os.Environ()...)
+	p.cmd.Env = append([]string{"NODE_OPTIONS=--max-old-space-size=4069"}, policy.Apply(os.Environ())...)
 	p.cmd.Env = append(p.cmd.Env, fmt.Sprintf("JSII_AGENT=%v/%v/%v", runtime.Version(), runtime.GOOS, runtime.GOARCH))
 
 	if stdin, err := p.cmd.StdinPipe(); err != nil {
@@ -119,15 +159,14 @@ func NewProcess(compatibleVersions string) (*Process, error) {
 		return nil, err
 	} else {
 		p.stdin = stdin
-		p.requests = json.NewEncoder(stdin)
 	}
 	if stdout, err := p.cmd.StdoutPipe(); err != nil {
 		p.Close()
 		return nil, err
 	} else {
 		p.stdout = stdout
-		p.responses = json.NewDecoder(stdout)
 	}
+	p.conn = protocol.NewSplitConn(p.stdout, p.stdin)
 	if stderr, err := p.cmd.StderrPipe(); err != nil {
 		p.Close()
 		return nil, err
@@ -135,161 +174,235 @@ func NewProcess(compatibleVersions string) (*Process, error) {
 		p.stderr = stderr
 	}
 
+	register(&p)
 	return &p, nil
 }
 
 func (p *Process) ensureStarted() error {
-	if p.closed {
+	p.startMutex.Lock()
+	defer p.startMutex.Unlock()
+
+	p.mutex.Lock()
+	closed, started := p.closed, p.started
+	p.mutex.Unlock()
+	if closed {
 		return fmt.Errorf("this process has been closed")
 	}
-	if p.started {
+	if started {
 		return nil
 	}
+
+	if p.embedded {
+		// We are about to run the embedded runtime, make sure node is usable.
+		if node, err := Preflight(); err != nil {
+			return p.startupError(err)
+		} else {
+			p.node = &node
+			p.cmd.Path = node.Path
+		}
+	}
 	if err := p.cmd.Start(); err != nil {
-		p.Close()
-		return err
+		return p.startupError(err)
 	}
+
+	p.mutex.Lock()
 	p.started = true
+	p.child = p.cmd.Process
+	p.mutex.Unlock()
+
+	go p.reap()
 
 	done := make(chan bool, 1)
 	go p.consumeStderr(done)
 	p.stderrDone = done
 
-	var handshake handshakeResponse
-	if err := p.readResponse(&handshake); err != nil {
-		p.Close()
-		return err
+	if err := p.readHandshake(&p.hello); err != nil {
+		return p.startupError(err)
 	}
+	p.mutex.Lock()
+	p.running = true
+	p.mutex.Unlock()
 
-	if runtimeVersion, err := handshake.runtimeVersion(); err != nil {
-		p.Close()
-		return err
+	if runtimeVersion, err := p.hello.RuntimeVersion(); err != nil {
+		return p.startupError(err)
 	} else if ok, errs := p.compatibleVersions.Validate(runtimeVersion); !ok {
 		causes := make([]string, len(errs))
 		for i, err := range errs {
 			causes[i] = fmt.Sprintf("- %v", err)
 		}
-		p.Close()
-		return fmt.Errorf("incompatible runtime version:\n%v", strings.Join(causes, "\n"))
+		return p.startupError(fmt.Errorf("incompatible runtime version:\n%v", strings.Join(causes, "\n")))
 	}
 
-	go func() {
-		err := p.cmd.Wait()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Runtime process exited abnormally: %v", err.Error())
-		}
-		p.Close()
-	}()
+	return nil
+}
 
+// Send starts the child process if that has not happened yet, then encodes the
+// supplied message and sends it to the child process. If the process is not in
+// a usable state, or if the encoding fails, an error is returned. Together with
+// Receive, this makes Process a protocol.Transport.
+func (p *Process) Send(message interface{}) error {
+	if err := p.ensureStarted(); err != nil {
+		return err
+	}
+	if err := p.send(message); err != nil {
+		p.Close()
+		return err
+	}
 	return nil
 }
 
+// Receive decodes the next message received from the child process into the
+// provided pointer. It must follow a call to Send.
+func (p *Process) Receive(into interface{}) error {
+	return p.readResponse(into)
+}
+
 // Request starts the child process if that has not happened yet, then
 // encodes the supplied request and sends it to the child process
 // via the requests channel, then decodes the response into the provided
 // response pointer. If the process is not in a usable state, or if the
 // encoding fails, an error is returned.
 func (p *Process) Request(request interface{}, response interface{}) error {
-	if err := p.ensureStarted(); err != nil {
+	if err := p.Send(request); err != nil {
 		return err
 	}
-	if err := p.requests.Encode(request); err != nil {
-		p.Close()
-		return err
-	}
-	return p.readResponse(response)
+	return p.Receive(response)
 }
 
-func (p *Process) readResponse(into interface{}) error {
-	if !p.responses.More() {
-		return fmt.Errorf("no response received from child process")
+// RequestPipelined starts the child process if that has not happened yet, then
+// encodes and sends all the supplied requests to the child process without
+// waiting for responses in between. Once all requests have been sent, one
+// response per request is decoded, in order, into the corresponding element of
+// responses. This saves a round-trip per request compared to Request.
+//
+// The returned slice holds one error per request (nil if its response was
+// successfully decoded), and is nil if all responses were successfully decoded.
+// All responses are consumed even if some of them cannot be decoded, so the
+// response stream remains in sync with the requests.
+func (p *Process) RequestPipelined(requests []interface{}, responses []interface{}) []error {
+	if len(requests) != len(responses) {
+		panic(fmt.Errorf("mismatched number of requests (%d) and responses (%d)", len(requests), len(responses)))
 	}
 
-	var raw json.RawMessage
-	var respmap map[string]interface{}
-	err := p.responses.Decode(&raw)
-	if err != nil {
-		return err
+	fail := func(err error) []error {
+		errs := make([]error, len(requests))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
 	}
 
-	err = json.Unmarshal(raw, &respmap)
-	if err != nil {
-		return err
+	if err := p.ensureStarted(); err != nil {
+		return fail(err)
+	}
+	if err := p.send(requests...); err != nil {
+		p.Close()
+		return fail(err)
 	}
 
-	var errResp ErrorResponse
-	if _, ok := respmap["error"]; ok {
-		json.Unmarshal(raw, &errResp)
-
-		if errResp.Name != nil && *errResp.Name == "@jsii/kernel.Fault" {
-			return fmt.Errorf("JsiiError: %s", *errResp.Name)
+	var errs []error
+	for i, response := range responses {
+		if err := p.readResponse(response); err != nil {
+			if errs == nil {
+				errs = make([]error, len(responses))
+			}
+			errs[i] = err
 		}
-
-		return errors.New(errResp.Error)
 	}
-
-	return json.Unmarshal(raw, &into)
+	return errs
 }
 
-func (p *Process) Close() {
-	if p.closed {
-		return
+// send sends the provided messages to the child process in order, without any
+// other message being sent in between. It stops at the first failure.
+func (p *Process) send(messages ...interface{}) error {
+	p.sendMutex.Lock()
+	defer p.sendMutex.Unlock()
+
+	for _, message := range messages {
+		if err := p.conn.Send(message); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Acquire the lock, so we don't try to concurrently close multiple times
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+// SetTrace makes the process write a copy of all messages exchanged with the
+// child process to out (see protocol.Conn.SetTrace). Passing nil stops tracing.
+func (p *Process) SetTrace(out io.Writer) {
+	p.conn.SetTrace(out)
+}
 
-	// Check again now that we own the lock, it may be a fast exit!
-	if p.closed {
-		return
-	}
+// Start starts the child process and completes the handshake, unless that
+// already happened. Requests do this automatically.
+func (p *Process) Start() error {
+	return p.ensureStarted()
+}
 
-	if p.stdin != nil {
-		// Try to send the exit message, this might fail, but we can ignore that.
-		p.stdin.Write([]byte("{\"exit\":0}\n"))
+// Hello returns the handshake message received from the child process. It is
+// empty until the process has started.
+func (p *Process) Hello() protocol.Hello {
+	return p.hello
+}
 
-		// Close STDIN for the child process now. Ignoring errors, as it may
-		// have been closed already (e.g: if the process exited).
-		p.stdin.Close()
-		p.stdin = nil
-	}
+// Entrypoint returns the path to the entry point of the embedded runtime, or
+// the JSII_RUNTIME command used instead of it.
+func (p *Process) Entrypoint() string {
+	return p.entrypoint
+}
 
-	if p.stdout != nil {
-		// Close STDOUT for the child process now, as we don't expect to receive
-		// responses anymore. Ignoring errors, as it may have been closed
-		// already (e.g: if the process exited).
-		p.stdout.Close()
-		p.stdout = nil
-	}
+// TempDir returns the temporary directory dedicated to this process, which is
+// removed when it is closed.
+func (p *Process) TempDir() string {
+	return p.tmpdir
+}
 
-	if p.stderrDone != nil {
-		// Wait for the stderr sink goroutine to have finished
-		<-p.stderrDone
-		p.stderrDone = nil
-	}
+// SetHandshakeTimeout changes how long the child process has to complete its
+// handshake once started. A zero or negative duration disables the timeout. It
+// has no effect once the process has started.
+func (p *Process) SetHandshakeTimeout(timeout time.Duration) {
+	p.handshakeTimeout = timeout
+}
 
-	if p.stderr != nil {
-		// Close STDERR for the child process now, as we're no longer consuming
-		// it anyway. Ignoring errors, as it may havebeen closed already (e.g:
-		// if the process exited).
-		p.stderr.Close()
-		p.stderr = nil
-	}
+// Node describes the node executable running the embedded runtime. It returns
+// nil until the process has started, or if a custom JSII_RUNTIME is used.
+func (p *Process) Node() *NodeInfo {
+	return p.node
+}
 
-	if p.cmd != nil {
-		// Wait for the child process to be dead and gone (should already be)
-		p.cmd.Wait()
-		p.cmd = nil
+// readHandshake reads the hello message from the child process, killing it if
+// it does not arrive within the handshake timeout.
+func (p *Process) readHandshake(handshake *protocol.Hello) error {
+	if p.handshakeTimeout <= 0 {
+		return p.readResponse(handshake)
 	}
 
-	if p.tmpdir != "" {
-		// Clean up any temporary directory we provisioned.
-		if err := os.RemoveAll(p.tmpdir); err != nil {
-			fmt.Fprintf(os.Stderr, "could not clean up temporary directory: %v\n", err)
-		}
-		p.tmpdir = ""
+	// Buffered, so the goroutine does not leak if the handshake times out. It
+	// decodes into its own value, which is only used once it is done.
+	var hello protocol.Hello
+	done := make(chan error, 1)
+	go func() { done <- p.readResponse(&hello) }()
+
+	timer := time.NewTimer(p.handshakeTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		*handshake = hello
+		return err
+	case <-timer.C:
+		// The goroutine is unblocked once the process is killed, or once its
+		// stdout is closed (whichever comes first).
+		p.child.Kill()
+		return fmt.Errorf("the child process did not complete the handshake within %v (set %v to change this timeout)", p.handshakeTimeout, JSII_HANDSHAKE_TIMEOUT)
 	}
+}
 
-	p.closed = true
+// readResponse decodes the next message received from the child process into
+// the provided value, in a single pass. Interpreting the message (e.g: to
+// identify error responses) is the caller's responsibility.
+func (p *Process) readResponse(into interface{}) error {
+	err := p.conn.Receive(into)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("no response received from child process")
+	}
+	return err
 }
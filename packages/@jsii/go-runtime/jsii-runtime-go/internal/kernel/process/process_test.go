@@ -2,6 +2,7 @@ package process
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 //go:embed jsii-mock-runtime.js
@@ -47,7 +49,7 @@ func TestVersionCheck(t *testing.T) {
 			}
 			defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
 
-			process, err := NewProcess(fmt.Sprintf("^4.3.2"))
+			process, err := NewProcess(fmt.Sprintf("^4.3.2"), EnvironmentPolicy{})
 			if err != nil {
 				t.Fatal(err)
 				return
@@ -80,7 +82,7 @@ func TestVersionCheck(t *testing.T) {
 			}
 			defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
 
-			process, err := NewProcess("^4.3.2")
+			process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
 			if err != nil {
 				t.Fatal(err)
 				return
@@ -101,6 +103,88 @@ func TestVersionCheck(t *testing.T) {
 	}
 }
 
+func TestHandshakeTimeout(t *testing.T) {
+	oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+	if runtime, err := makeCustomRuntime("4.3.2 silent"); err != nil {
+		t.Fatal(err)
+	} else {
+		os.Setenv(JSII_RUNTIME, runtime)
+	}
+	defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer process.Close()
+	process.SetHandshakeTimeout(500 * time.Millisecond)
+
+	err = process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{})
+	var startupErr *StartupError
+	if !errors.As(err, &startupErr) {
+		t.Fatalf("expected a *StartupError, got %#v", err)
+	}
+	if !strings.Contains(startupErr.Cause.Error(), "did not complete the handshake within 500ms") {
+		t.Errorf("expected a handshake timeout, got %v", startupErr.Cause)
+	}
+	if !strings.Contains(err.Error(), "JSII_RUNTIME="+os.Getenv(JSII_RUNTIME)) {
+		t.Errorf("expected the error to report JSII_RUNTIME, got %v", err)
+	}
+}
+
+func TestStartupErrorReportsStderr(t *testing.T) {
+	oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+	if runtime, err := makeCustomRuntime("4.3.2 crash"); err != nil {
+		t.Fatal(err)
+	} else {
+		os.Setenv(JSII_RUNTIME, runtime)
+	}
+	defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer process.Close()
+	command := strings.Join(process.cmd.Args, " ")
+
+	err = process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{})
+	var startupErr *StartupError
+	if !errors.As(err, &startupErr) {
+		t.Fatalf("expected a *StartupError, got %#v", err)
+	}
+	if !strings.Contains(startupErr.Stderr, "Cannot find module '@mock/jsii-runtime@4.3.2'") {
+		t.Errorf("expected stderr to be captured, got %q", startupErr.Stderr)
+	}
+	if !strings.Contains(err.Error(), "command: "+command) {
+		t.Errorf("expected the error to report the command line, got %v", err)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	oldPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", oldPath)
+
+	if node, err := Preflight(); err != nil {
+		t.Fatal(err)
+	} else if node.Version == nil || node.Path == "" {
+		t.Errorf("expected node to be described, got %#v", node)
+	}
+
+	os.Setenv("PATH", t.TempDir())
+	if _, err := Preflight(); err == nil || !strings.Contains(err.Error(), "could not find node") {
+		t.Errorf("expected node not to be found, got %v", err)
+	}
+}
+
+func TestCheckNodeVersion(t *testing.T) {
+	for output, ok := range map[string]bool{"v14.6.0\n": true, "v20.1.0": true, "v12.22.12\n": false, "nope": false} {
+		if _, err := checkNodeVersion(output); (err == nil) != ok {
+			t.Errorf("unexpected outcome for %q: %v", output, err)
+		}
+	}
+}
+
 type EchoRequest struct {
 	Message string `json:"message"`
 }
@@ -0,0 +1,179 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// JSII_HANDSHAKE_TIMEOUT is the name of the environment variable that
+	// overrides DefaultHandshakeTimeout, using the time.ParseDuration format. A
+	// zero or negative duration disables the timeout.
+	JSII_HANDSHAKE_TIMEOUT = "JSII_HANDSHAKE_TIMEOUT"
+
+	// DefaultHandshakeTimeout is how long the child process has to send its
+	// hello message once started. It is generous, as loading the runtime bundle
+	// can take a while on busy machines.
+	DefaultHandshakeTimeout = time.Minute
+
+	// MinimumNodeVersion is the oldest node release supported by the embedded
+	// @jsii/runtime, as declared in its package.json "engines" field.
+	MinimumNodeVersion = "14.6.0"
+)
+
+// stderrTailSize bounds how much of the child process' stderr is kept around
+// for inclusion in a StartupError.
+const stderrTailSize = 8 * 1024
+
+// startupEnvironment lists the environment variables that influence how the
+// child process starts, which are reported in a StartupError.
+var startupEnvironment = []string{JSII_RUNTIME, JSII_HANDSHAKE_TIMEOUT, "NODE_OPTIONS", "JSII_AGENT", "JSII_DEBUG"}
+
+// NodeInfo describes the node executable used to run the embedded runtime.
+type NodeInfo struct {
+	// Path is the location of the node executable.
+	Path string
+	// Version is the version reported by node --version.
+	Version *semver.Version
+}
+
+func (n NodeInfo) String() string {
+	return fmt.Sprintf("%v (v%v)", n.Path, n.Version)
+}
+
+// Preflight locates the node executable on the PATH and checks that its
+// version is at least MinimumNodeVersion.
+func Preflight() (NodeInfo, error) {
+	var info NodeInfo
+
+	path, err := exec.LookPath("node")
+	if err != nil {
+		return info, fmt.Errorf("could not find node on the PATH, it must be installed to use jsii libraries (https://nodejs.org): %v", err)
+	}
+	info.Path = path
+
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return info, fmt.Errorf("could not determine the version of %v: %v", path, err)
+	}
+	info.Version, err = checkNodeVersion(string(output))
+	return info, err
+}
+
+// checkNodeVersion parses the output of node --version, and checks it is at
+// least MinimumNodeVersion.
+func checkNodeVersion(output string) (*semver.Version, error) {
+	version, err := semver.NewVersion(strings.TrimPrefix(strings.TrimSpace(output), "v"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse node version %q: %v", strings.TrimSpace(output), err)
+	}
+	if version.LessThan(semver.MustParse(MinimumNodeVersion)) {
+		return version, fmt.Errorf("node v%v is not supported, v%v or later is required", version, MinimumNodeVersion)
+	}
+	return version, nil
+}
+
+// handshakeTimeout returns the default handshake timeout, as overridden by
+// the JSII_HANDSHAKE_TIMEOUT environment variable (invalid values are ignored).
+func handshakeTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(os.Getenv(JSII_HANDSHAKE_TIMEOUT)); err == nil {
+		return timeout
+	}
+	return DefaultHandshakeTimeout
+}
+
+// StartupError is returned when the child process could not be started, or
+// did not complete the handshake. It includes what is needed to diagnose the
+// problem, in particular what the child process wrote to stderr.
+type StartupError struct {
+	// Command is the command line used to start the child process.
+	Command []string
+	// Node describes the node executable, if it was located.
+	Node *NodeInfo
+	// Environment lists the variables that influence startup, in KEY=VALUE
+	// form, as they were passed to the child process.
+	Environment []string
+	// Stderr is the last few kilobytes the child process wrote to stderr.
+	Stderr string
+	// Cause is the reason why startup failed.
+	Cause error
+}
+
+func (e *StartupError) Error() string {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "could not start the jsii runtime: %v", e.Cause)
+	fmt.Fprintf(&msg, "\n\tcommand: %v", strings.Join(e.Command, " "))
+	if e.Node != nil {
+		fmt.Fprintf(&msg, "\n\tnode: %v", e.Node)
+	}
+	if len(e.Environment) > 0 {
+		msg.WriteString("\n\tenvironment:")
+		for _, kv := range e.Environment {
+			fmt.Fprintf(&msg, "\n\t\t%v", kv)
+		}
+	}
+	if stderr := strings.TrimRight(e.Stderr, "\n"); stderr != "" {
+		msg.WriteString("\n\tstderr:")
+		for _, line := range strings.Split(stderr, "\n") {
+			fmt.Fprintf(&msg, "\n\t\t%v", line)
+		}
+	}
+	return msg.String()
+}
+
+func (e *StartupError) Unwrap() error {
+	return e.Cause
+}
+
+// startupError creates a StartupError for the provided cause, then closes the
+// process (which waits for its stderr to be fully consumed).
+func (p *Process) startupError(cause error) *StartupError {
+	err := &StartupError{Command: p.cmd.Args, Node: p.node, Cause: cause}
+
+	// The last value wins when a variable is set more than once.
+	values := make(map[string]string)
+	for _, kv := range p.cmd.Env {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			values[kv[:i]] = kv
+		}
+	}
+	for _, name := range startupEnvironment {
+		if kv, ok := values[name]; ok {
+			err.Environment = append(err.Environment, kv)
+		}
+	}
+
+	p.Close()
+	err.Stderr = p.stderrTail.String()
+	return err
+}
+
+// tailBuffer retains the last bytes written to it, up to its size.
+type tailBuffer struct {
+	size  int
+	data  []byte
+	mutex sync.Mutex
+}
+
+func (t *tailBuffer) Write(data []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.data = append(t.data, data...)
+	if excess := len(t.data) - t.size; excess > 0 {
+		t.data = append(t.data[:0], t.data[excess:]...)
+	}
+	return len(data), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return string(t.data)
+}
@@ -0,0 +1,85 @@
+package process
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tempDirPattern is the pattern of the names of the temporary directories
+	// created by NewProcess.
+	tempDirPattern = "jsii-runtime.*"
+	// ownerFile is the name of the file recording the PID of the program that
+	// owns a temporary directory.
+	ownerFile = ".owner"
+	// unownedMaxAge is how old a temporary directory without an ownerFile (as
+	// created by older releases) must be before it is considered abandoned.
+	unownedMaxAge = 24 * time.Hour
+)
+
+var sweepOnce sync.Once
+
+// sweepOrphansOnce removes orphaned temporary directories in the background,
+// the first time it is called.
+func sweepOrphansOnce() {
+	sweepOnce.Do(func() {
+		go SweepOrphans(os.TempDir())
+	})
+}
+
+// makeTempDir creates a new temporary directory for a process, recording the
+// current program as its owner.
+func makeTempDir() (string, error) {
+	tmpdir, err := ioutil.TempDir("", tempDirPattern)
+	if err != nil {
+		return "", err
+	}
+	owner := fmt.Sprintf("%d\n", os.Getpid())
+	if err := ioutil.WriteFile(filepath.Join(tmpdir, ownerFile), []byte(owner), 0o600); err != nil {
+		os.RemoveAll(tmpdir)
+		return "", err
+	}
+	return tmpdir, nil
+}
+
+// SweepOrphans removes the temporary directories created by NewProcess in the
+// provided directory that were left behind by programs that are no longer
+// running (e.g: because they were killed before they could clean up). It
+// returns the paths of the removed directories.
+func SweepOrphans(dir string) (removed []string, err error) {
+	candidates, err := filepath.Glob(filepath.Join(dir, tempDirPattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if orphaned(candidate) && os.RemoveAll(candidate) == nil {
+			removed = append(removed, candidate)
+		}
+	}
+	return removed, nil
+}
+
+// orphaned determines whether the provided temporary directory was abandoned
+// by its owner.
+func orphaned(tmpdir string) bool {
+	info, err := os.Stat(tmpdir)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+
+	owner, err := ioutil.ReadFile(filepath.Join(tmpdir, ownerFile))
+	if err != nil {
+		return os.IsNotExist(err) && time.Since(info.ModTime()) > unownedMaxAge
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(owner)))
+	if err != nil {
+		return false
+	}
+	return pid != os.Getpid() && !processExists(pid)
+}
@@ -0,0 +1,122 @@
+package process
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSweepOrphans(t *testing.T) {
+	// A process that has exited provides the PID of a dead owner.
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not available")
+	}
+	dead := exec.Command(node, "-e", "")
+	if err := dead.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	makeDir := func(name string, owner int, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.Mkdir(path, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if owner != 0 {
+			if err := ioutil.WriteFile(filepath.Join(path, ownerFile), []byte(fmt.Sprintln(owner)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		modTime := time.Now().Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	makeDir("jsii-runtime.live", os.Getpid(), 0)
+	orphan := makeDir("jsii-runtime.orphan", dead.Process.Pid, 0)
+	legacy := makeDir("jsii-runtime.legacy", 0, 2*unownedMaxAge)
+	makeDir("jsii-runtime.recent", 0, 0)
+	makeDir("unrelated.dir", dead.Process.Pid, 0)
+
+	removed, err := SweepOrphans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(removed)
+	if expected := []string{legacy, orphan}; !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected %v to be removed, got %v", expected, removed)
+	}
+	for _, name := range []string{"jsii-runtime.live", "jsii-runtime.recent", "unrelated.dir"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %v to be retained: %v", name, err)
+		}
+	}
+}
+
+func TestTerminateAll(t *testing.T) {
+	oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+	if runtime, err := makeCustomRuntime("4.3.2"); err != nil {
+		t.Fatal(err)
+	} else {
+		os.Setenv(JSII_RUNTIME, runtime)
+	}
+	defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer process.Close()
+	if err := process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := ioutil.ReadFile(filepath.Join(process.TempDir(), ownerFile)); err != nil || string(owner) != fmt.Sprintln(os.Getpid()) {
+		t.Errorf("expected the temporary directory to record its owner, got %q (%v)", owner, err)
+	}
+
+	if forced := TerminateAll(10 * time.Second); forced != 0 {
+		t.Errorf("expected the process to exit gracefully, but %d did not", forced)
+	}
+	if _, err := os.Stat(process.TempDir()); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary directory to be removed, got %v", err)
+	}
+	if err := process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{}); err == nil {
+		t.Errorf("expected the process to be closed")
+	}
+}
+
+func TestKill(t *testing.T) {
+	oldJsiiRuntime := os.Getenv(JSII_RUNTIME)
+	if runtime, err := makeCustomRuntime("4.3.2"); err != nil {
+		t.Fatal(err)
+	} else {
+		os.Setenv(JSII_RUNTIME, runtime)
+	}
+	defer os.Setenv(JSII_RUNTIME, oldJsiiRuntime)
+
+	process, err := NewProcess("^4.3.2", EnvironmentPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer process.Close()
+	if err := process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{}); err != nil {
+		t.Fatal(err)
+	}
+
+	process.Kill()
+	if _, err := os.Stat(process.TempDir()); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary directory to be removed, got %v", err)
+	}
+	if err := process.Request(EchoRequest{Message: "Oh, hi!"}, &EchoResponse{}); err == nil {
+		t.Errorf("expected the killed process not to respond")
+	}
+}
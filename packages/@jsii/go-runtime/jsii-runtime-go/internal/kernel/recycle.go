@@ -0,0 +1,158 @@
+package kernel
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/objectstore"
+)
+
+const (
+	// JSII_RECYCLE_MAX_RSS is the name of the environment variable that sets
+	// the default RecyclePolicy.MaxRSS, in mebibytes.
+	JSII_RECYCLE_MAX_RSS = "JSII_RECYCLE_MAX_RSS"
+	// JSII_RECYCLE_MAX_HEAP is the name of the environment variable that sets
+	// the default RecyclePolicy.MaxHeapUsed, in mebibytes.
+	JSII_RECYCLE_MAX_HEAP = "JSII_RECYCLE_MAX_HEAP"
+
+	// recycleCloseTimeout is how long a recycled process has to exit gracefully
+	// (see CloseClientWithTimeout).
+	recycleCloseTimeout = 10 * time.Second
+)
+
+// RecyclePolicy sets thresholds on the memory used by the @jsii/kernel process,
+// above which it is replaced by a new one (recycled), so that programs running
+// for a long time do not exhaust the node heap. Recycling happens only at safe
+// points, where no kernel object can be used anymore, which the program
+// declares using SafePoint: the runtime cannot tell on its own when the kernel
+// objects it handed out are no longer referenced. Libraries that were loaded
+// are loaded again into the new process.
+//
+// Recycling is disabled unless at least one of the thresholds is set.
+type RecyclePolicy struct {
+	// MaxRSS is the resident set size of the process, in bytes, above which it
+	// is recycled. Zero means no limit.
+	MaxRSS uint64
+	// MaxHeapUsed is the size of the node heap in use, in bytes, above which
+	// the process is recycled. Zero means no limit.
+	MaxHeapUsed uint64
+}
+
+// Enabled returns true if at least one of the thresholds is set.
+func (p RecyclePolicy) Enabled() bool {
+	return p.MaxRSS > 0 || p.MaxHeapUsed > 0
+}
+
+// Exceeded returns true if the provided memory usage crosses any of the
+// thresholds.
+func (p RecyclePolicy) Exceeded(usage MemoryUsage) bool {
+	return (p.MaxRSS > 0 && usage.RSS > p.MaxRSS) || (p.MaxHeapUsed > 0 && usage.HeapUsed > p.MaxHeapUsed)
+}
+
+var (
+	recyclePolicy     RecyclePolicy
+	recyclePolicyOnce sync.Once
+	recyclePolicyLock sync.RWMutex
+)
+
+// GetRecyclePolicy returns the policy currently applied to the @jsii/kernel
+// process. Unless SetRecyclePolicy was called, it is read from the
+// JSII_RECYCLE_MAX_RSS and JSII_RECYCLE_MAX_HEAP environment variables, with
+// invalid values being ignored.
+func GetRecyclePolicy() RecyclePolicy {
+	recyclePolicyOnce.Do(func() {
+		var policy RecyclePolicy
+		if mebibytes, err := strconv.ParseUint(os.Getenv(JSII_RECYCLE_MAX_RSS), 10, 64); err == nil {
+			policy.MaxRSS = mebibytes << 20
+		}
+		if mebibytes, err := strconv.ParseUint(os.Getenv(JSII_RECYCLE_MAX_HEAP), 10, 64); err == nil {
+			policy.MaxHeapUsed = mebibytes << 20
+		}
+		recyclePolicy = policy
+	})
+
+	recyclePolicyLock.RLock()
+	defer recyclePolicyLock.RUnlock()
+	return recyclePolicy
+}
+
+// SetRecyclePolicy changes the policy applied to the @jsii/kernel process, and
+// returns the previous one. The change applies to the next SafePoint.
+func SetRecyclePolicy(policy RecyclePolicy) (previous RecyclePolicy) {
+	previous = GetRecyclePolicy()
+
+	recyclePolicyLock.Lock()
+	defer recyclePolicyLock.Unlock()
+	recyclePolicy = policy
+	return
+}
+
+// SafePoint declares that none of the kernel objects obtained so far will be
+// used anymore. If the RecyclePolicy is enabled, the memory used by the
+// @jsii/kernel process is sampled, and the process is recycled if it crosses
+// any of the thresholds, which makes all these objects unusable. It returns
+// whether the process was recycled. It has no effect if the process has not
+// started yet, or while a callback or asynchronous invocation is in progress.
+func (c *Client) SafePoint() (recycled bool, err error) {
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+
+	policy := GetRecyclePolicy()
+	if !policy.Enabled() || c.wire == nil || !c.conversation.quiescent() {
+		return false, nil
+	}
+
+	usage, err := c.sampleMemory()
+	if err != nil || !policy.Exceeded(usage) {
+		return false, err
+	}
+	return true, c.recycle()
+}
+
+// recycle replaces the @jsii/kernel process by a new one, into which the
+// assemblies loaded so far are loaded again, and forgets all kernel objects.
+// The caller must have acquired the conversation with the kernel, at a safe
+// point.
+func (c *Client) recycle() error {
+	next, err := newProcess()
+	if err != nil {
+		return err
+	}
+
+	c.processLock.Lock()
+	if c.closed {
+		c.processLock.Unlock()
+		next.Close()
+		return fmt.Errorf("the client has been closed")
+	}
+	previous := c.process
+	c.process = next
+	if c.trace != nil {
+		next.SetTrace(c.trace)
+	}
+	c.processLock.Unlock()
+
+	// The previous process is closed first, so its memory is released before
+	// the new process loads the assemblies.
+	if _, err := previous.CloseWithTimeout(recycleCloseTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "could not close the recycled kernel process: %v\n", err)
+	}
+
+	c.wire = nil
+	c.objects = objectstore.New()
+	c.recycles++
+
+	assemblies := c.assemblies
+	c.loaded = make(map[LoadProps]LoadResponse)
+	c.assemblies = nil
+	c.hierarchy, c.hierarchyLoaded = nil, 0
+	for _, assembly := range assemblies {
+		if _, err := c.load(assembly.props, assembly.tarball); err != nil {
+			return fmt.Errorf("could not load %v@%v into the recycled kernel process: %v", assembly.props.Name, assembly.props.Version, err)
+		}
+	}
+	return nil
+}
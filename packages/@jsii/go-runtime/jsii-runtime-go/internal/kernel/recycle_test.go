@@ -0,0 +1,92 @@
+package kernel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+func withRecyclePolicy(policy RecyclePolicy, cb func()) {
+	previous := SetRecyclePolicy(policy)
+	defer SetRecyclePolicy(previous)
+	cb()
+}
+
+// loadedAs loads a library into the kernel, and returns the assembly name
+// reported by the fake kernel, which identifies the kernel process.
+func loadedAs(t *testing.T, client *Client, name string) string {
+	response, err := client.Load(LoadProps{Name: name, Version: "1.2.3"}, []byte("tarball"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Assembly
+}
+
+func TestMemoryUsage(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		usage, err := client.MemoryUsage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.RSS == 0 || usage.HeapUsed == 0 || usage.HeapTotal < usage.HeapUsed {
+			t.Errorf("expected memory figures from the kernel, got %#v", usage)
+		}
+		if usage.KernelObjects != 0 || usage.References != 0 || usage.Recycles != 0 {
+			t.Errorf("expected no objects and no recycles, got %#v", usage)
+		}
+	})
+}
+
+func TestRecycleOnlyAtSafePoints(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		withRecyclePolicy(RecyclePolicy{MaxHeapUsed: 1}, func() {
+			before := loadedAs(t, client, "lib")
+			if _, err := client.Stats(); err != nil {
+				t.Fatal(err)
+			}
+			if loadedAs(t, client, "lib") != before {
+				t.Errorf("expected the kernel not to be recycled outside of a safe point")
+			}
+		})
+
+		if usage, err := client.MemoryUsage(); err != nil {
+			t.Fatal(err)
+		} else if usage.Recycles != 0 {
+			t.Errorf("expected no recycles, got %#v", usage)
+		}
+	})
+}
+
+func TestSafePoint(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		before := loadedAs(t, client, "lib")
+		objref := api.ObjectRef{InstanceID: "test.Receiver@1"}
+		if err := client.RegisterInstance(reflect.ValueOf(&struct{ api.ObjectRef }{}), objref); err != nil {
+			t.Fatal(err)
+		}
+
+		if recycled, err := client.SafePoint(); err != nil || recycled {
+			t.Errorf("expected no recycle without a policy, got %v (%v)", recycled, err)
+		}
+
+		withRecyclePolicy(RecyclePolicy{MaxHeapUsed: 1 << 40}, func() {
+			if recycled, err := client.SafePoint(); err != nil || recycled {
+				t.Errorf("expected no recycle below the thresholds, got %v (%v)", recycled, err)
+			}
+		})
+
+		withRecyclePolicy(RecyclePolicy{MaxHeapUsed: 1}, func() {
+			if recycled, err := client.SafePoint(); err != nil || !recycled {
+				t.Errorf("expected a recycle above the thresholds, got %v (%v)", recycled, err)
+			}
+		})
+
+		if after := loadedAs(t, client, "lib"); before == after {
+			t.Errorf("expected the library to be loaded again")
+		}
+		if _, found := client.objects.GetObject(objref.InstanceID); found {
+			t.Errorf("expected objects to be forgotten once the kernel is recycled")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package kernel
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ScriptResult is the outcome of a script run using RunScript.
+type ScriptResult struct {
+	// Status is the exit status of the script, or -1 if it was terminated by a
+	// signal.
+	Status int
+	// Stdout is what the script wrote to its standard output.
+	Stdout []byte
+	// Stderr is what the script wrote to its standard error.
+	Stderr []byte
+}
+
+// RunScript runs a script from the "bin" section of a loaded library's
+// package.json in a child process of this program, using the command returned
+// by GetScriptCommand, and returns its outcome once it has exited. Like with
+// InvokeScript, the command is run through the system shell, but its arguments
+// are quoted for it (/bin/sh, or cmd.exe on Windows), so they are passed
+// verbatim. The script does not read from standard input. A script
+// exiting with a non-zero status is not an error.
+func (c *Client) RunScript(props GetScriptCommandProps) (result ScriptResult, err error) {
+	command, err := c.GetScriptCommand(props)
+	if err != nil {
+		return
+	}
+
+	cmd := shellCommand(command.Command, command.Args)
+	// The kernel provides the complete environment, sorted for reproducibility.
+	cmd.Env = make([]string, 0, len(command.Env))
+	for key, value := range command.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	sort.Strings(cmd.Env)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+
+	result.Status = cmd.ProcessState.ExitCode()
+	result.Stdout, result.Stderr = stdout.Bytes(), stderr.Bytes()
+	return
+}
+
+// cmdLine returns the arguments of cmd.exe to run the provided program with the
+// provided arguments, as a single string (cmd.exe does not parse its command
+// line like other programs do). Each argument is quoted the way programs using
+// the Microsoft C runtime expect, then the characters cmd.exe interprets are
+// escaped with "^", so that the program receives it verbatim. Batch files (such
+// as npm's shims) are parsed by cmd.exe a second time, so the escaping is then
+// applied twice.
+func cmdLine(program string, args []string) string {
+	ext := strings.ToLower(filepath.Ext(program))
+	batch := ext == ".cmd" || ext == ".bat"
+
+	line := make([]string, 0, len(args)+1)
+	line = append(line, cmdMetaChars.ReplaceAllString(program, "^$1"))
+	for _, arg := range args {
+		line = append(line, cmdQuote(arg, batch))
+	}
+	return `/d /s /c "` + strings.Join(line, " ") + `"`
+}
+
+var (
+	// cmdMetaChars matches the characters cmd.exe may interpret.
+	cmdMetaChars = regexp.MustCompile("([()\\][%!^\"`<>&|;, *?])")
+	// cmdQuotedBackslashes matches backslashes followed by a double quote.
+	cmdQuotedBackslashes = regexp.MustCompile(`(\\*)"`)
+	// cmdTrailingBackslashes matches backslashes at the end of the string.
+	cmdTrailingBackslashes = regexp.MustCompile(`(\\*)$`)
+)
+
+// cmdQuote quotes the provided argument for cmd.exe (see cmdLine).
+func cmdQuote(arg string, batch bool) string {
+	// Backslashes are only special before a double quote, which includes the
+	// closing one.
+	arg = cmdQuotedBackslashes.ReplaceAllString(arg, `$1$1\"`)
+	arg = cmdTrailingBackslashes.ReplaceAllString(arg, "$1$1")
+	arg = cmdMetaChars.ReplaceAllString(`"`+arg+`"`, "^$1")
+	if batch {
+		arg = cmdMetaChars.ReplaceAllString(arg, "^$1")
+	}
+	return arg
+}
@@ -0,0 +1,63 @@
+package kernel
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestRunScript(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		script := `console.log(process.argv.slice(1).join('|')); console.error(process.env.FAKE_KERNEL); process.exit(3)`
+		result, err := client.RunScript(GetScriptCommandProps{Assembly: "test-lib", Script: script, Args: []string{"a", "b c", "$HOME", "it's", "a&b|c", "%PATH%", `say "hi"`}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != 3 {
+			t.Errorf("expected exit status 3, got %v", result.Status)
+		}
+		if stdout := string(result.Stdout); stdout != "a|b c|$HOME|it's|a&b|c|%PATH%|say \"hi\"\n" {
+			t.Errorf("expected the arguments on stdout, got %q", stdout)
+		}
+		if stderr := string(result.Stderr); stderr != "test-lib\n" {
+			t.Errorf("expected the kernel-provided environment, got %q", stderr)
+		}
+	})
+}
+
+func TestShellCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the POSIX shell is not used on windows")
+	}
+	if _, err := exec.LookPath("/bin/sh"); err != nil {
+		t.Skip("/bin/sh is not available")
+	}
+
+	cmd := shellCommand("/path/to/script", []string{"a", "b c", "it's"})
+	if expected := []string{"/bin/sh", "-c", `'/path/to/script' 'a' 'b c' 'it'\''s'`}; !reflect.DeepEqual(cmd.Args, expected) {
+		t.Errorf("expected %q, got %q", expected, cmd.Args)
+	}
+
+	// Scripts are resolved by the shell, like the kernel does.
+	output, err := shellCommand("printf", []string{"%s|", "a", "b c"}).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "a|b c|" {
+		t.Errorf("expected the arguments to be passed verbatim, got %q", output)
+	}
+}
+
+func TestCmdLine(t *testing.T) {
+	line := cmdLine(`C:\lib\bin\script.js`, []string{"a", "b c", `say "hi"`, "a&b|c", "%PATH%", `trailing\`})
+	if expected := `/d /s /c "C:\lib\bin\script.js ^"a^" ^"b^ c^" ^"say^ \^"hi\^"^" ^"a^&b^|c^" ^"^%PATH^%^" ^"trailing\\^""`; line != expected {
+		t.Errorf("expected %v, got %v", expected, line)
+	}
+
+	// Batch files parse their arguments once more.
+	line = cmdLine(`C:\lib\node_modules\.bin\tool.CMD`, []string{"a&b"})
+	if expected := `/d /s /c "C:\lib\node_modules\.bin\tool.CMD ^^^"a^^^&b^^^""`; line != expected {
+		t.Errorf("expected %v, got %v", expected, line)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package kernel
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// shellCommand returns a command that runs the provided program with the
+// provided arguments through the system shell, the same way the kernel runs
+// scripts (i.e: node's child_process with the "shell" option), so that scripts
+// are resolved identically. Unlike the kernel, this quotes the program and
+// arguments, so they are passed verbatim.
+func shellCommand(program string, args []string) *exec.Cmd {
+	line := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{program}, args...) {
+		line = append(line, shellQuote(arg))
+	}
+	return exec.Command("/bin/sh", "-c", strings.Join(line, " "))
+}
+
+// shellQuote quotes the provided string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package kernel
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand returns a command that runs the provided program with the
+// provided arguments through cmd.exe, the same way the kernel runs scripts
+// (i.e: node's child_process with the "shell" option), so that scripts are
+// resolved identically (e.g: npm's shims). Unlike the kernel, this quotes the
+// program and arguments, so they are passed verbatim. The command line is
+// provided as-is, since cmd.exe does not follow the conventions exec.Command
+// quotes arguments for.
+func shellCommand(program string, args []string) *exec.Cmd {
+	cmd := exec.Command("cmd.exe")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "cmd.exe " + cmdLine(program, args)}
+	return cmd
+}
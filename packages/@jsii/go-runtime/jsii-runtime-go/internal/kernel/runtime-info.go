@@ -0,0 +1,78 @@
+package kernel
+
+import (
+	"github.com/aws/jsii-runtime-go/internal/embedded"
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+// RuntimeInfo describes the @jsii/kernel process a Client communicates with.
+type RuntimeInfo struct {
+	// Package is the name of the kernel's package (e.g: @jsii/runtime).
+	Package string
+	// Version is the version of the kernel's package.
+	Version string
+	// NodePath is the location of the node executable running the kernel, or
+	// "" if a custom JSII_RUNTIME is used.
+	NodePath string
+	// NodeVersion is the version of node running the kernel, or "" if a custom
+	// JSII_RUNTIME is used.
+	NodeVersion string
+	// BundleHash is the SHA-256 digest of the embedded kernel bundle (see
+	// embedded.BundleHash), or "" if a custom JSII_RUNTIME is used.
+	BundleHash string
+	// RuntimePath is the path to the entry point of the kernel, or the custom
+	// JSII_RUNTIME command used instead.
+	RuntimePath string
+	// Features lists the protocol features negotiated with the kernel.
+	Features []protocol.Feature
+}
+
+// RuntimeInfo describes the @jsii/kernel process, starting it if that has not
+// happened yet.
+func (c *Client) RuntimeInfo() (info RuntimeInfo, err error) {
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+
+	if err = c.negotiate(); err != nil {
+		return
+	}
+
+	hello := c.process.Hello()
+	info.Package = hello.RuntimePackage()
+	if version, err := hello.RuntimeVersion(); err == nil {
+		info.Version = version.String()
+	}
+	if node := c.process.Node(); node != nil {
+		info.NodePath, info.NodeVersion = node.Path, node.Version.String()
+		info.BundleHash = embedded.BundleHash()
+	}
+	info.RuntimePath = c.process.Entrypoint()
+	info.Features = c.wire.Features().Sorted()
+	return
+}
+
+// Supports returns true if the kernel supports the provided protocol feature,
+// starting the kernel process if that has not happened yet. Features that are
+// not supported are disabled, and using the APIs that are part of them fails
+// with a *protocol.MissingFeatureError.
+func (c *Client) Supports(feature protocol.Feature) bool {
+	c.conversation.acquire(nil)
+	defer c.conversation.release()
+
+	return c.negotiate() == nil && c.wire.Features().Has(feature)
+}
+
+// negotiate starts the kernel process if that has not happened yet, and
+// determines which protocol features it supports. The caller must have acquired
+// the conversation with the kernel.
+func (c *Client) negotiate() error {
+	if c.wire != nil {
+		return nil
+	}
+	if err := c.process.Start(); err != nil {
+		return err
+	}
+
+	c.wire = protocol.Attach(c.process, c.process.Hello(), nil)
+	return nil
+}
@@ -0,0 +1,93 @@
+package kernel
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/kernel/process"
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+func TestRuntimeInfo(t *testing.T) {
+	withFakeKernel(t, 1, func(client *Client) {
+		info, err := client.RuntimeInfo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Package != "@fake/jsii-runtime" || info.Version != version {
+			t.Errorf("expected @fake/jsii-runtime@%v, got %v@%v", version, info.Package, info.Version)
+		}
+		if !strings.Contains(info.RuntimePath, "jsii-fake-kernel.") {
+			t.Errorf("expected the JSII_RUNTIME command, got %v", info.RuntimePath)
+		}
+		if info.NodePath != "" || info.BundleHash != "" {
+			t.Errorf("expected no node or bundle details for a custom runtime, got %#v", info)
+		}
+		if !reflect.DeepEqual(info.Features, []protocol.Feature{protocol.FeatureAsync, protocol.FeatureNaming, protocol.FeatureScripts, protocol.FeatureStats}) {
+			t.Errorf("expected the baseline features, got %v", info.Features)
+		}
+	})
+}
+
+func TestEmbeddedRuntimeAdvertisesFeatures(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not available")
+	}
+	if os.Getenv(process.JSII_RUNTIME) != "" {
+		t.Skipf("%v is set, so the embedded runtime is not used", process.JSII_RUNTIME)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.close()
+
+	info, err := client.RuntimeInfo()
+	if err != nil {
+		t.Skipf("the embedded runtime could not be started, it may not have been built: %v", err)
+	}
+	if hello := client.process.Hello(); hello.Features == nil {
+		t.Errorf("expected %v to advertise its features", hello.Hello)
+	}
+	if expected := []protocol.Feature{protocol.FeatureAsync, protocol.FeatureNaming, protocol.FeatureScripts, protocol.FeatureStats}; !reflect.DeepEqual(info.Features, expected) {
+		t.Errorf("expected %v, got %v", expected, info.Features)
+	}
+}
+
+func TestMissingFeature(t *testing.T) {
+	oldFeatures, hadFeatures := os.LookupEnv("FAKE_KERNEL_FEATURES")
+	os.Setenv("FAKE_KERNEL_FEATURES", "naming")
+	defer func() {
+		if hadFeatures {
+			os.Setenv("FAKE_KERNEL_FEATURES", oldFeatures)
+		} else {
+			os.Unsetenv("FAKE_KERNEL_FEATURES")
+		}
+	}()
+
+	withFakeKernel(t, 1, func(client *Client) {
+		if client.Supports(protocol.FeatureAsync) || !client.Supports(protocol.FeatureNaming) {
+			t.Errorf("expected only the naming feature to be supported")
+		}
+
+		_, err := client.InvokeAsync(InvokeProps{Method: "echo", ObjRef: api.ObjectRef{InstanceID: "test.Level@1"}})
+		var missing *protocol.MissingFeatureError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected a *protocol.MissingFeatureError, got %#v", err)
+		}
+		if !reflect.DeepEqual(missing.Features, []protocol.Feature{protocol.FeatureAsync}) || missing.API != "begin" {
+			t.Errorf("expected the async feature to be missing for begin, got %v", err)
+		}
+
+		// Other APIs remain usable.
+		if _, err := client.Invoke(InvokeProps{Method: "echo", ObjRef: api.ObjectRef{InstanceID: "test.Level@1"}}); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -1,44 +1,19 @@
 package kernel
 
-import "github.com/aws/jsii-runtime-go/internal/api"
+import "github.com/aws/jsii-runtime-go/protocol"
 
-type SetProps struct {
-	Property string        `json:"property"`
-	Value    interface{}   `json:"value"`
-	ObjRef   api.ObjectRef `json:"objref"`
-}
-
-type StaticSetProps struct {
-	FQN      api.FQN     `json:"fqn"`
-	Property string      `json:"property"`
-	Value    interface{} `json:"value"`
-}
-
-type SetResponse struct {
-	kernelResponse
-}
+type (
+	SetProps       = protocol.SetProps
+	StaticSetProps = protocol.StaticSetProps
+	SetResponse    = protocol.SetResponse
+)
 
 func (c *Client) Set(props SetProps) (response SetResponse, err error) {
-	type request struct {
-		kernelRequest
-		SetProps
-	}
-	err = c.request(request{kernelRequest{"set"}, props}, &response)
+	err = c.request(protocol.NewRequest("set", props), &response)
 	return
 }
 
 func (c *Client) SSet(props StaticSetProps) (response SetResponse, err error) {
-	type request struct {
-		kernelRequest
-		StaticSetProps
-	}
-	err = c.request(request{kernelRequest{"sset"}, props}, &response)
+	err = c.request(protocol.NewRequest("sset", props), &response)
 	return
 }
-
-// UnmarshalJSON provides custom unmarshalling implementation for response
-// structs. Creating new types is required in order to avoid infinite recursion.
-func (r *SetResponse) UnmarshalJSON(data []byte) error {
-	type response SetResponse
-	return unmarshalKernelResponse(data, (*response)(r), r)
-}
@@ -0,0 +1,70 @@
+package kernel
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel/process"
+)
+
+// DefaultSignalTimeout is how long HandleSignals lets the @jsii/kernel process
+// terminate gracefully by default, before killing it.
+const DefaultSignalTimeout = 5 * time.Second
+
+// HandleSignals makes sure the @jsii/kernel process and its temporary files are
+// cleaned up if this program receives one of the provided signals (os.Interrupt
+// and syscall.SIGTERM if none are provided). Upon receiving one, the kernel is
+// requested to exit and given up to timeout to do so (DefaultSignalTimeout if
+// zero or less), after which it is terminated forcibly (see
+// CloseClientWithTimeout); any other kernel process this program started is
+// then terminated the same way. As with CloseClient, a new kernel process is
+// started if the client is used afterwards. The signal is then raised again, so that the
+// program terminates as it would have without this handling. The program is
+// not exited otherwise: if the signal does not terminate it (e.g: because it is
+// also handled using signal.Notify elsewhere, or because it cannot be raised on
+// this platform), the caller is responsible for terminating it.
+//
+// The returned function stops handling the signals.
+func HandleSignals(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	if timeout <= 0 {
+		timeout = DefaultSignalTimeout
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	received := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+	signal.Notify(received, signals...)
+
+	go func() {
+		select {
+		case sig := <-received:
+			// Detaching the singleton client, so it is not used once closed.
+			stage, err := CloseClientWithTimeout(timeout)
+			if forced := process.TerminateAll(timeout); forced > 0 || stage > process.CloseStageExit || err != nil {
+				fmt.Fprintf(os.Stderr, "jsii kernel process did not exit within %v after %v, it was terminated forcibly\n", timeout, sig)
+			}
+			signal.Stop(received)
+			raise(sig)
+		case <-stopped:
+			signal.Stop(received)
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(stopped) }) }
+}
+
+// raise delivers the provided signal to this program again, now that it is no
+// longer handled by HandleSignals. Whether that terminates the program is up to
+// the signal's disposition.
+func raise(sig os.Signal) {
+	if self, err := os.FindProcess(os.Getpid()); err == nil {
+		self.Signal(sig)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build !windows
+// +build !windows
+
+package kernel
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+// TestSignalHandlingHelper is not a test of its own: it is run in a separate
+// process by TestHandleSignals, and waits to be terminated by a signal. If
+// JSII_SIGNAL_HELPER is "notify", it also handles SIGTERM itself, and a while
+// after the signal is raised again, exits successfully if the client can still
+// be used (with a new kernel process).
+func TestSignalHandlingHelper(t *testing.T) {
+	mode := os.Getenv("JSII_SIGNAL_HELPER")
+	if mode == "" {
+		t.Skip("only run by TestHandleSignals")
+	}
+	withFakeKernel(t, 1, func(client *Client) {
+		clientInstanceMutex.Lock()
+		clientInstance = client
+		clientInstanceMutex.Unlock()
+
+		if mode == "notify" {
+			own := make(chan os.Signal, 2)
+			signal.Notify(own, syscall.SIGTERM)
+			go func() {
+				// The signal is received once when sent, and once when raised.
+				<-own
+				<-own
+				time.Sleep(2 * time.Second)
+				_, err := GetClient().Get(GetProps{ObjRef: api.ObjectRef{InstanceID: "test.Level@1"}, Property: "field0"})
+				CloseClient()
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				os.Exit(0)
+			}()
+		}
+		HandleSignals(10 * time.Second)
+		if _, err := client.Get(GetProps{ObjRef: api.ObjectRef{InstanceID: "test.Level@1"}, Property: "field0"}); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Printf("ready %v %v\n", client.process.TempDir(), strings.Fields(os.Getenv("JSII_RUNTIME"))[1])
+		select {}
+	})
+}
+
+func TestHandleSignals(t *testing.T) {
+	helper, tmpdir := signalHandlingHelper(t, "1")
+	defer helper.Process.Kill()
+
+	if err := helper.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	err := helper.Wait()
+	if status, ok := helper.ProcessState.Sys().(syscall.WaitStatus); !ok || !status.Signaled() || status.Signal() != syscall.SIGTERM {
+		t.Errorf("expected the helper to be terminated by SIGTERM, got %v", err)
+	}
+	if _, err := os.Stat(tmpdir); !os.IsNotExist(err) {
+		t.Errorf("expected the kernel's temporary directory to be removed, got %v", err)
+	}
+}
+
+func TestHandleSignalsLeavesTerminationToCaller(t *testing.T) {
+	helper, tmpdir := signalHandlingHelper(t, "notify")
+	defer helper.Process.Kill()
+
+	if err := helper.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if err := helper.Wait(); err != nil {
+		t.Errorf("expected the helper to exit on its own terms, got %v", err)
+	}
+	if _, err := os.Stat(tmpdir); !os.IsNotExist(err) {
+		t.Errorf("expected the kernel's temporary directory to be removed, got %v", err)
+	}
+}
+
+// signalHandlingHelper starts TestSignalHandlingHelper in the provided mode,
+// and waits until it is ready. It returns the helper's command, and the
+// temporary directory of its kernel process.
+func signalHandlingHelper(t *testing.T, mode string) (*exec.Cmd, string) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not available")
+	}
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestSignalHandlingHelper$")
+	helper.Env = append(os.Environ(), "JSII_SIGNAL_HELPER="+mode)
+	stdout, err := helper.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helper.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	fields := strings.Fields(line)
+	if err != nil || len(fields) != 3 || fields[0] != "ready" {
+		t.Fatalf("unexpected output from the helper: %q (%v)", line, err)
+	}
+	tmpdir, script := fields[1], fields[2]
+	t.Cleanup(func() { os.Remove(script) })
+	return helper, tmpdir
+}
@@ -1,11 +1,10 @@
 package kernel
 
-type StatsResponse struct {
-	kernelResponse
-	ObjectCount float64 `json:"object_count"`
-}
+import "github.com/aws/jsii-runtime-go/protocol"
+
+type StatsResponse = protocol.StatsResponse
 
 func (c *Client) Stats() (response StatsResponse, err error) {
-	err = c.request(kernelRequest{"stats"}, &response)
+	err = c.request(protocol.NewRequest("stats", nil), &response)
 	return
 }
@@ -0,0 +1,313 @@
+package kernel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// MarshalStruct returns the JSON encoding of the provided jsii struct value (or
+// pointer to one). Fields are named after the jsii property names, nested
+// structs are encoded as plain objects, enum values as their member name, and
+// dates as RFC3339 strings. Returns an error if the value is not a registered
+// jsii struct, if a required field is nil, or if it contains object
+// references (which cannot be encoded).
+func (c *Client) MarshalStruct(v interface{}) ([]byte, error) {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if !val.IsValid() {
+		return nil, fmt.Errorf("cannot marshal a nil value, a jsii struct is required")
+	}
+	if _, _, isStruct := c.Types().StructFields(val.Type()); !isStruct {
+		return nil, fmt.Errorf("%v is not a registered jsii struct type", val.Type())
+	}
+
+	data, err := c.toJSONValue(val, val.Type().Name())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalStruct decodes the provided JSON document (in the format produced
+// by MarshalStruct) into the jsii struct pointed to by v. Returns an error if
+// v is not a pointer to a registered jsii struct, if the document has
+// properties that do not exist on the struct, lacks required ones, or has
+// values of the wrong type.
+func (c *Client) UnmarshalStruct(data []byte, v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if !ptr.IsValid() {
+		return fmt.Errorf("the target must be a non-nil pointer to a jsii struct; received nil")
+	}
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("the target must be a non-nil pointer to a jsii struct; received %v", ptr.Type())
+	}
+	if _, _, isStruct := c.Types().StructFields(ptr.Elem().Type()); !isStruct {
+		return fmt.Errorf("%v is not a registered jsii struct type", ptr.Elem().Type())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the top-level JSON value")
+	}
+	return c.fromJSONValue(decoded, ptr.Elem(), ptr.Elem().Type().Name())
+}
+
+// toJSONValue converts the provided value into its generic JSON
+// representation (as used by encoding/json). The path is used to produce
+// meaningful error messages.
+func (c *Client) toJSONValue(val reflect.Value, path string) (interface{}, error) {
+	if !val.IsValid() {
+		return nil, nil
+	}
+	if (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil() {
+		return nil, nil
+	}
+
+	if wireDate, isDate := castPtrToDate(val); isDate {
+		return wireDate.Timestamp, nil
+	}
+
+	switch val.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if ref, isRef := c.FindObjectRef(val); isRef {
+			return nil, fmt.Errorf("%v: cannot encode object reference %v", path, ref.InstanceID)
+		}
+		return c.toJSONValue(val.Elem(), path)
+
+	case reflect.Struct:
+		fields, _, isStruct := c.Types().StructFields(val.Type())
+		if !isStruct {
+			return nil, fmt.Errorf("%v: cannot encode %v, as it is not a registered jsii struct type", path, val.Type())
+		}
+		result := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			key := field.Tag.Get("json")
+			fieldVal := val.FieldByIndex(field.Index)
+			if (fieldVal.Kind() == reflect.Ptr || fieldVal.Kind() == reflect.Interface) && fieldVal.IsNil() {
+				if isRequired(field) {
+					return nil, fmt.Errorf("%v.%v: field is required, but has nil value", path, key)
+				}
+				continue
+			}
+			encoded, err := c.toJSONValue(fieldVal, fmt.Sprintf("%v.%v", path, key))
+			if err != nil {
+				return nil, err
+			}
+			if encoded != nil {
+				result[key] = encoded
+			}
+		}
+		return result, nil
+
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%v: cannot encode map with non-string keys %v", path, val.Type())
+		}
+		result := make(map[string]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			encoded, err := c.toJSONValue(iter.Value(), fmt.Sprintf("%v[%q]", path, key))
+			if err != nil {
+				return nil, err
+			}
+			result[key] = encoded
+		}
+		return result, nil
+
+	case reflect.Slice, reflect.Array:
+		result := make([]interface{}, val.Len())
+		for i := range result {
+			encoded, err := c.toJSONValue(val.Index(i), fmt.Sprintf("%v[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = encoded
+		}
+		return result, nil
+
+	case reflect.String:
+		// Enum values are their member name, and the zero-value means no value.
+		if _, isEnum := c.Types().EnumFQN(val.Type()); isEnum && val.String() == "" {
+			return nil, nil
+		}
+		return val.String(), nil
+
+	case reflect.Bool:
+		return val.Bool(), nil
+
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		num, err := castIntegerToNumber(val)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+		return num, nil
+
+	default:
+		return nil, fmt.Errorf("%v: cannot encode value of type %v", path, val.Type())
+	}
+}
+
+// fromJSONValue decodes the provided generic JSON representation (as produced
+// by encoding/json) into the provided settable value. The path is used to
+// produce meaningful error messages.
+func (c *Client) fromJSONValue(data interface{}, val reflect.Value, path string) error {
+	if data == nil {
+		val.Set(reflect.Zero(val.Type()))
+		return nil
+	}
+
+	typ := val.Type()
+	if typ == timeType {
+		str, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("%v: expected an RFC3339 date string, received %v", path, data)
+		}
+		date, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		val.Set(reflect.ValueOf(date))
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(typ.Elem())
+		if err := c.fromJSONValue(data, elem.Elem(), path); err != nil {
+			return err
+		}
+		val.Set(elem)
+		return nil
+
+	case reflect.Interface:
+		if typ != anyType {
+			return fmt.Errorf("%v: cannot decode object reference of type %v", path, typ)
+		}
+		val.Set(reflect.ValueOf(genericJSONValue(data)))
+		return nil
+
+	case reflect.Struct:
+		fields, _, isStruct := c.Types().StructFields(typ)
+		if !isStruct {
+			return fmt.Errorf("%v: cannot decode %v, as it is not a registered jsii struct type", path, typ)
+		}
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected an object, received %v", path, data)
+		}
+		known := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			key := field.Tag.Get("json")
+			known[key] = true
+			fieldData, present := obj[key]
+			if !present || fieldData == nil {
+				if isRequired(field) {
+					return fmt.Errorf("%v.%v: field is required, but is missing", path, key)
+				}
+				continue
+			}
+			if err := c.fromJSONValue(fieldData, val.FieldByIndex(field.Index), fmt.Sprintf("%v.%v", path, key)); err != nil {
+				return err
+			}
+		}
+		for key := range obj {
+			if !known[key] {
+				return fmt.Errorf("%v: unknown property %q", path, key)
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		obj, ok := data.(map[string]interface{})
+		if !ok || typ.Key().Kind() != reflect.String {
+			return fmt.Errorf("%v: cannot decode %v into %v", path, data, typ)
+		}
+		result := reflect.MakeMapWithSize(typ, len(obj))
+		for key, elemData := range obj {
+			elem := reflect.New(typ.Elem()).Elem()
+			if err := c.fromJSONValue(elemData, elem, fmt.Sprintf("%v[%q]", path, key)); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
+		}
+		val.Set(result)
+		return nil
+
+	case reflect.Slice:
+		arr, ok := data.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected an array, received %v", path, data)
+		}
+		result := reflect.MakeSlice(typ, len(arr), len(arr))
+		for i, elemData := range arr {
+			if err := c.fromJSONValue(elemData, result.Index(i), fmt.Sprintf("%v[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		val.Set(result)
+		return nil
+
+	case reflect.String:
+		str, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("%v: expected a string, received %v", path, data)
+		}
+		if fqn, isEnum := c.Types().EnumFQN(typ); isEnum {
+			member, err := c.Types().EnumMemberForEnumRef(api.EnumRef{MemberFQN: fmt.Sprintf("%v/%v", fqn, str)})
+			if err != nil {
+				return fmt.Errorf("%v: %v", path, err)
+			}
+			val.Set(reflect.ValueOf(member))
+			return nil
+		}
+		val.Set(reflect.ValueOf(str).Convert(typ))
+		return nil
+
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return fmt.Errorf("%v: expected a boolean, received %v", path, data)
+		}
+		val.SetBool(b)
+		return nil
+
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		num, isNum, err := castValToNumber(reflect.ValueOf(data), typ)
+		if !isNum {
+			return fmt.Errorf("%v: expected a number, received %v", path, data)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		val.Set(num)
+		return nil
+
+	default:
+		return fmt.Errorf("%v: cannot decode value into %v", path, typ)
+	}
+}
+
+// isRequired determines whether the provided struct field is marked as
+// required in its "field" tag.
+func isRequired(field reflect.StructField) bool {
+	requiredOrOptional, found := field.Tag.Lookup("field")
+	return found && requiredOrOptional == "required"
+}
@@ -0,0 +1,386 @@
+package kernel
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"time"
+)
+
+// StructEqual determines whether the two provided jsii struct values (or
+// pointers to such) are equal. Struct fields are compared by value, following
+// pointers (so two distinct pointers to equal values are equal); nil values
+// are only equal to other nil values; collections are compared element-wise;
+// and object references are compared by identity. Panics if either value is
+// not a registered jsii struct or pointer to one.
+func (c *Client) StructEqual(a, b interface{}) bool {
+	c.assertStruct(a)
+	c.assertStruct(b)
+	return c.valuesEqual(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// StructHash computes a hash code for the provided jsii struct value (or
+// pointer to one). Values that are equal according to StructEqual have the same
+// hash code. Panics if the value is not a registered jsii struct or pointer to
+// one.
+func (c *Client) StructHash(v interface{}) uint64 {
+	c.assertStruct(v)
+	h := fnv.New64a()
+	c.hashValue(h, reflect.ValueOf(v))
+	return h.Sum64()
+}
+
+// StructClone returns a deep copy of the provided jsii struct value (or pointer
+// to one), with the same type as the provided value. Pointers, collections and
+// nested structs are copied, while object references are shared with the
+// original value. Panics if the value is not a registered jsii struct or
+// pointer to one.
+func (c *Client) StructClone(v interface{}) interface{} {
+	c.assertStruct(v)
+	return c.cloneValue(reflect.ValueOf(v)).Interface()
+}
+
+// assertStruct panics if v is not a registered jsii struct type, or a pointer
+// to one.
+func (c *Client) assertStruct(v interface{}) {
+	typ := reflect.TypeOf(v)
+	if typ == nil {
+		panic(fmt.Errorf("expected a jsii struct, but received nil"))
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if _, _, isStruct := c.Types().StructFields(typ); !isStruct {
+		panic(fmt.Errorf("%v is not a registered jsii struct type", typ))
+	}
+}
+
+// resolve unwraps interfaces and pointers around the provided value. It stops
+// at pointers to structs that are neither jsii structs nor time.Time, as those
+// are object references, which have identity semantics.
+func (c *Client) resolve(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && !v.IsNil() {
+		if v.Kind() == reflect.Ptr && c.isObjectType(v.Type().Elem()) {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isObjectType determines whether values of the provided type are object
+// references (as opposed to jsii structs or other data types).
+func (c *Client) isObjectType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+	_, _, isStruct := c.Types().StructFields(typ)
+	return !isStruct
+}
+
+// isUnset determines whether a resolved value represents no value at all.
+func isUnset(v reflect.Value) bool {
+	return !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil())
+}
+
+func (c *Client) valuesEqual(a, b reflect.Value) bool {
+	a, b = c.resolve(a), c.resolve(b)
+	if isUnset(a) || isUnset(b) {
+		return isUnset(a) && isUnset(b)
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		return c.SameObject(a, b)
+
+	case reflect.Struct:
+		if a.Type() == timeType {
+			return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+		}
+		fields, _, isStruct := c.Types().StructFields(a.Type())
+		if !isStruct {
+			// Other structs have no jsii semantics, and their fields may not be
+			// exported.
+			return a.CanInterface() && b.CanInterface() && reflect.DeepEqual(a.Interface(), b.Interface())
+		}
+		for _, field := range fields {
+			if !c.valuesEqual(a.FieldByIndex(field.Index), b.FieldByIndex(field.Index)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !c.valuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			other := b.MapIndex(iter.Key())
+			if !other.IsValid() || !c.valuesEqual(iter.Value(), other) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return a.Type().Comparable() && a.Interface() == b.Interface()
+	}
+}
+
+func (c *Client) hashValue(h hash.Hash64, v reflect.Value) {
+	v = c.resolve(v)
+	if isUnset(v) {
+		h.Write([]byte{0})
+		return
+	}
+
+	buf := make([]byte, 8)
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf, u)
+		h.Write(buf)
+	}
+	writeString := func(s string) {
+		writeUint(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if ref, found := c.FindObjectRef(v); found {
+			writeString(ref.InstanceID)
+		} else {
+			writeUint(uint64(v.Pointer()))
+		}
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			writeUint(uint64(v.Interface().(time.Time).UnixNano()))
+			return
+		}
+		fields, fqn, isStruct := c.Types().StructFields(v.Type())
+		if !isStruct {
+			writeString(v.Type().String())
+			hashDeep(h, v, 0)
+			return
+		}
+		writeString(string(fqn))
+		for _, field := range fields {
+			c.hashValue(h, v.FieldByIndex(field.Index))
+		}
+
+	case reflect.Slice, reflect.Array:
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			c.hashValue(h, v.Index(i))
+		}
+
+	case reflect.Map:
+		// Map iteration order is random, so entries are combined using an
+		// order-independent operation.
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := fnv.New64a()
+			c.hashValue(entry, iter.Key())
+			c.hashValue(entry, iter.Value())
+			sum += entry.Sum64()
+		}
+		writeUint(uint64(v.Len()))
+		writeUint(sum)
+
+	case reflect.String:
+		writeString(v.String())
+
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			// Ensures -0 and +0 (which are equal) hash the same.
+			f = 0
+		}
+		writeUint(math.Float64bits(f))
+
+	default:
+		writeString(v.Type().String())
+	}
+}
+
+// maxHashDepth bounds how deep hashDeep follows pointers, so that it terminates
+// on cyclic values.
+const maxHashDepth = 32
+
+// hashDeep hashes the provided value consistently with reflect.DeepEqual (which
+// StructEqual uses for structs that are not jsii structs): pointers and
+// interfaces are followed, and all struct fields are hashed, exported or not.
+func hashDeep(h hash.Hash64, v reflect.Value, depth int) {
+	buf := make([]byte, 8)
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf, u)
+		h.Write(buf)
+	}
+	if depth > maxHashDepth || !v.IsValid() {
+		writeUint(0)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+		} else {
+			writeUint(1)
+			hashDeep(h, v.Elem(), depth+1)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashDeep(h, v.Field(i), depth+1)
+		}
+
+	case reflect.Slice, reflect.Array:
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			hashDeep(h, v.Index(i), depth+1)
+		}
+
+	case reflect.Map:
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := fnv.New64a()
+			hashDeep(entry, iter.Key(), depth+1)
+			hashDeep(entry, iter.Value(), depth+1)
+			sum += entry.Sum64()
+		}
+		writeUint(uint64(v.Len()))
+		writeUint(sum)
+
+	case reflect.String:
+		writeUint(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			f = 0
+		}
+		writeUint(math.Float64bits(f))
+
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+
+	default:
+		// Functions and channels are only deeply equal if both are nil.
+		writeUint(uint64(v.Kind()))
+	}
+}
+
+func (c *Client) cloneValue(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || c.isObjectType(v.Type().Elem()) {
+			return v
+		}
+		result := reflect.New(v.Type().Elem())
+		result.Elem().Set(c.cloneValue(v.Elem()))
+		return result
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.New(v.Type()).Elem()
+		result.Set(c.cloneValue(v.Elem()))
+		return result
+
+	case reflect.Struct:
+		fields, _, isStruct := c.Types().StructFields(v.Type())
+		if !isStruct {
+			return v
+		}
+		result := reflect.New(v.Type()).Elem()
+		for _, field := range fields {
+			result.FieldByIndex(field.Index).Set(c.cloneValue(v.FieldByIndex(field.Index)))
+		}
+		return result
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(c.cloneValue(v.Index(i)))
+		}
+		return result
+
+	case reflect.Array:
+		result := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(c.cloneValue(v.Index(i)))
+		}
+		return result
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), c.cloneValue(iter.Value()))
+		}
+		return result
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,115 @@
+package kernel
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+// typeHierarchy maps the FQNs of the types declared by loaded assemblies to
+// their direct supertypes: the base class and implemented interfaces of
+// classes, and the extended interfaces of interfaces.
+type typeHierarchy map[api.FQN][]api.FQN
+
+// assemblyMetadata is the part of a jsii assembly (the .jsii file of a library
+// package) that describes its type hierarchy. The .jsii file may instead be a
+// redirect to a (compressed) file holding the assembly.
+type assemblyMetadata struct {
+	Schema      string `json:"schema"`
+	Filename    string `json:"filename"`
+	Compression string `json:"compression"`
+
+	Types map[api.FQN]struct {
+		Base       api.FQN   `json:"base"`
+		Interfaces []api.FQN `json:"interfaces"`
+	} `json:"types"`
+}
+
+// typeHierarchy returns the type hierarchy declared by the assemblies loaded so
+// far. Assemblies are read from their tarball the first time this is needed,
+// and those that cannot be read are ignored. The caller must have acquired the
+// conversation with the kernel.
+func (c *Client) typeHierarchy() typeHierarchy {
+	if c.hierarchy == nil {
+		c.hierarchy = make(typeHierarchy)
+	}
+	for ; c.hierarchyLoaded < len(c.assemblies); c.hierarchyLoaded++ {
+		metadata, err := readAssemblyMetadata(c.assemblies[c.hierarchyLoaded].tarball)
+		if err != nil {
+			continue
+		}
+		for fqn, typ := range metadata.Types {
+			supertypes := typ.Interfaces
+			if typ.Base != "" {
+				supertypes = append([]api.FQN{typ.Base}, supertypes...)
+			}
+			c.hierarchy[fqn] = supertypes
+		}
+	}
+	return c.hierarchy
+}
+
+// readAssemblyMetadata reads the jsii assembly from the provided npm package
+// tarball, following the redirect the .jsii file may contain.
+func readAssemblyMetadata(tarball []byte) (*assemblyMetadata, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return nil, err
+	}
+
+	// The redirect target may appear anywhere in the tarball, so all candidate
+	// files are retained.
+	files := make(map[string][]byte)
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || path.Dir(header.Name) != "package" {
+			continue
+		}
+		if name := path.Base(header.Name); name == ".jsii" || path.Ext(name) == ".gz" {
+			if files[name], err = ioutil.ReadAll(reader); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	data, found := files[".jsii"]
+	if !found {
+		return nil, fmt.Errorf("the tarball does not contain a .jsii assembly")
+	}
+	var metadata assemblyMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Schema != "jsii/file-redirect" {
+		return &metadata, nil
+	}
+
+	if data, found = files[path.Base(metadata.Filename)]; !found {
+		return nil, fmt.Errorf("the tarball does not contain the assembly %v redirects to", metadata.Filename)
+	}
+	if metadata.Compression != "gzip" {
+		return nil, fmt.Errorf("unsupported assembly compression: %q", metadata.Compression)
+	}
+	if gz, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	metadata = assemblyMetadata{}
+	if err := json.NewDecoder(gz).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
@@ -38,15 +38,21 @@ type ObjectStore struct {
 	// Incorrect use of the UnsafeCast function may result in an instance's
 	// interface list containing interfaces that it does not actually implement.
 	idToInterfaces map[string]stringSet
+
+	// idToAssumedInterfaces associates an instanceID with the subset of its
+	// interfaces that were only asserted by user code (e.g: via UnsafeCast), and
+	// were never declared by the kernel nor at object creation time.
+	idToAssumedInterfaces map[string]stringSet
 }
 
 // New initializes a new ObjectStore.
 func New() *ObjectStore {
 	return &ObjectStore{
-		objectToID:     make(map[uintptr]string),
-		idToObject:     make(map[string]reflect.Value),
-		idToObjects:    make(map[string]map[reflect.Value]struct{}),
-		idToInterfaces: make(map[string]stringSet),
+		objectToID:            make(map[uintptr]string),
+		idToObject:            make(map[string]reflect.Value),
+		idToObjects:           make(map[string]map[reflect.Value]struct{}),
+		idToInterfaces:        make(map[string]stringSet),
+		idToAssumedInterfaces: make(map[string]stringSet),
 	}
 }
 
@@ -136,9 +142,32 @@ func (o *ObjectStore) mergeInterfaces(objectRef api.ObjectRef) {
 	}
 
 	// Add any missing interface to the list.
+	assumed := o.idToAssumedInterfaces[objectRef.InstanceID]
 	for _, iface := range objectRef.Interfaces {
 		interfaces[string(iface)] = struct{}{}
+		// The interface is now declared, so it is no longer merely assumed.
+		delete(assumed, string(iface))
+	}
+}
+
+// AssumeInterface records that the provided instanceID implements the given
+// interface, based solely on the caller's assertion. Unless it was otherwise
+// declared, the interface is included in the Interfaces list, but not in the
+// DeclaredInterfaces list.
+func (o *ObjectStore) AssumeInterface(instanceID string, iface api.FQN) {
+	if set, found := o.idToInterfaces[instanceID]; found {
+		if _, known := set[string(iface)]; known {
+			return
+		}
+	} else {
+		o.idToInterfaces[instanceID] = make(stringSet)
 	}
+	o.idToInterfaces[instanceID][string(iface)] = struct{}{}
+
+	if _, found := o.idToAssumedInterfaces[instanceID]; !found {
+		o.idToAssumedInterfaces[instanceID] = make(stringSet)
+	}
+	o.idToAssumedInterfaces[instanceID][string(iface)] = struct{}{}
 }
 
 // InstanceID attempts to determine the instanceID associated with the provided
@@ -173,6 +202,26 @@ func (o *ObjectStore) Interfaces(instanceID string) []api.FQN {
 	}
 }
 
+// DeclaredInterfaces returns the set of interfaces associated with the provided
+// instanceID, excluding those that were only assumed via AssumeInterface.
+//
+// It returns a nil slice in case the instanceID is invalid, or if it does not
+// have any declared interfaces.
+func (o *ObjectStore) DeclaredInterfaces(instanceID string) []api.FQN {
+	set, found := o.idToInterfaces[instanceID]
+	if !found {
+		return nil
+	}
+	assumed := o.idToAssumedInterfaces[instanceID]
+	var interfaces []api.FQN
+	for iface := range set {
+		if _, isAssumed := assumed[iface]; !isAssumed {
+			interfaces = append(interfaces, api.FQN(iface))
+		}
+	}
+	return interfaces
+}
+
 // GetObject attempts to retrieve the object value associated with the given
 // instanceID. Returns the existing value and a boolean informing whether a
 // value was associated with this instanceID or not.
@@ -184,6 +233,12 @@ func (o *ObjectStore) GetObject(instanceID string) (value reflect.Value, found b
 	return
 }
 
+// Count returns the number of instanceIDs that have been registered with the
+// ObjectStore.
+func (o *ObjectStore) Count() int {
+	return len(o.idToObject)
+}
+
 // GetObjectAs attempts to retrieve the object value associated with the given
 // instanceID, compatible with the given type. Returns the existing value and a
 // boolean informing whether a value was associated with this instanceID and
@@ -7,10 +7,58 @@ import (
 	"github.com/aws/jsii-runtime-go/internal/api"
 )
 
+// implementation is the result of DiscoverImplementation for a given type.
+type implementation struct {
+	interfaces []api.FQN
+	overrides  []api.Override
+}
+
 // DiscoverImplementation determines the list of registered interfaces that are
 // implemented by the provided type, and returns the list of their FQNs and
-// overrides for all their combined methods and properties.
+// overrides for all their combined methods and properties. Results are cached
+// per type until another class or interface gets registered.
 func (t *TypeRegistry) DiscoverImplementation(vt reflect.Type) (interfaces []api.FQN, overrides []api.Override) {
+	t.implementationsMutex.RLock()
+	cached, found := t.implementations[vt]
+	generation := t.implementationsGeneration
+	t.implementationsMutex.RUnlock()
+
+	if !found {
+		cached.interfaces, cached.overrides = t.discoverImplementation(vt)
+		t.cacheImplementation(vt, cached, generation)
+	}
+
+	// Capping capacity so that callers appending to the results do not modify
+	// the cached slices.
+	interfaces = cached.interfaces[:len(cached.interfaces):len(cached.interfaces)]
+	overrides = cached.overrides[:len(cached.overrides):len(cached.overrides)]
+	return
+}
+
+// cacheImplementation caches the implementation discovered for vt, unless the
+// cache was reset since the provided generation, as it may then not account for
+// the types registered in the meantime.
+func (t *TypeRegistry) cacheImplementation(vt reflect.Type, impl implementation, generation uint64) {
+	t.implementationsMutex.Lock()
+	defer t.implementationsMutex.Unlock()
+
+	if t.implementationsGeneration == generation {
+		t.implementations[vt] = impl
+	}
+}
+
+// resetImplementations discards all cached DiscoverImplementation results.
+func (t *TypeRegistry) resetImplementations() {
+	t.implementationsMutex.Lock()
+	defer t.implementationsMutex.Unlock()
+
+	t.implementationsGeneration++
+	if len(t.implementations) > 0 {
+		t.implementations = make(map[reflect.Type]implementation)
+	}
+}
+
+func (t *TypeRegistry) discoverImplementation(vt reflect.Type) (interfaces []api.FQN, overrides []api.Override) {
 	if strings.HasPrefix(vt.Name(), "jsiiProxy_") {
 		return
 	}
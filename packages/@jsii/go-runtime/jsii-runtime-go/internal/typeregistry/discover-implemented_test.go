@@ -0,0 +1,69 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+)
+
+type IGreeter interface {
+	Greet() string
+}
+
+type IWaver interface {
+	Wave()
+}
+
+type greeterAndWaver struct{}
+
+func (g *greeterAndWaver) Greet() string { return "Hello" }
+func (g *greeterAndWaver) Wave()         {}
+
+func TestDiscoverImplementationCacheInvalidation(t *testing.T) {
+	registry := New()
+	greeter := api.MethodOverride{JsiiMethod: "greet", GoMethod: "Greet"}
+	if err := registry.RegisterInterface("test.IGreeter", reflect.TypeOf((*IGreeter)(nil)).Elem(), []api.Override{greeter}, func() interface{} { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	vt := reflect.TypeOf((*greeterAndWaver)(nil))
+	interfaces, overrides := registry.DiscoverImplementation(vt)
+	if !reflect.DeepEqual(interfaces, []api.FQN{"test.IGreeter"}) || len(overrides) != 1 {
+		t.Errorf("unexpected implementation: %v, %v", interfaces, overrides)
+	}
+
+	// Appending to the results must not affect the cache.
+	_ = append(interfaces, "test.Bogus")
+	if cached, _ := registry.DiscoverImplementation(vt); !reflect.DeepEqual(cached, []api.FQN{"test.IGreeter"}) {
+		t.Errorf("cached result was modified: %v", cached)
+	}
+
+	// Registering a new interface invalidates the cache.
+	waver := api.MethodOverride{JsiiMethod: "wave", GoMethod: "Wave"}
+	if err := registry.RegisterInterface("test.IWaver", reflect.TypeOf((*IWaver)(nil)).Elem(), []api.Override{waver}, func() interface{} { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if interfaces, overrides := registry.DiscoverImplementation(vt); len(interfaces) != 2 || len(overrides) != 2 {
+		t.Errorf("expected both interfaces, got: %v, %v", interfaces, overrides)
+	}
+}
+
+func TestDiscoverImplementationConcurrentReset(t *testing.T) {
+	registry := New()
+	vt := reflect.TypeOf((*greeterAndWaver)(nil))
+
+	// A result computed before a registration completes must not be cached.
+	registry.implementationsMutex.RLock()
+	generation := registry.implementationsGeneration
+	registry.implementationsMutex.RUnlock()
+	greeter := api.MethodOverride{JsiiMethod: "greet", GoMethod: "Greet"}
+	if err := registry.RegisterInterface("test.IGreeter", reflect.TypeOf((*IGreeter)(nil)).Elem(), []api.Override{greeter}, func() interface{} { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	registry.cacheImplementation(vt, implementation{}, generation)
+
+	if interfaces, _ := registry.DiscoverImplementation(vt); !reflect.DeepEqual(interfaces, []api.FQN{"test.IGreeter"}) {
+		t.Errorf("expected the stale result to be discarded, got %v", interfaces)
+	}
+}
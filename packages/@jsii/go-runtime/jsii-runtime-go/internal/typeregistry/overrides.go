@@ -4,14 +4,15 @@ import (
 	"github.com/aws/jsii-runtime-go/internal/api"
 )
 
+// GetOverride returns the member of the type designated by fqn that has the
+// provided go name, if there is one.
 func (t *TypeRegistry) GetOverride(fqn api.FQN, n string) (api.Override, bool) {
-	if members, ok := t.typeMembers[fqn]; ok {
-		for _, member := range members {
-			if member.GoName() == n {
-				return member, true
-			}
-		}
-	}
+	member, ok := t.typeMembersByGoName[fqn][n]
+	return member, ok
+}
 
-	return nil, false
+// Members returns all registered members of the type designated by fqn.
+func (t *TypeRegistry) Members(fqn api.FQN) []api.Override {
+	members := t.typeMembers[fqn]
+	return members[:len(members):len(members)]
 }
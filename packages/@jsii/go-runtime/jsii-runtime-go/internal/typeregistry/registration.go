@@ -43,13 +43,32 @@ func (t *TypeRegistry) RegisterClass(fqn api.FQN, class reflect.Type, overrides
 	t.fqnToType[fqn] = registeredType{class, classType}
 	t.proxyMakers[class] = maker
 
+	t.registerMembers(fqn, overrides)
+
+	return nil
+}
+
+// registerMembers records the provided overrides as the members of the type
+// designated by fqn, and invalidates any cached implementation discovery once
+// they are recorded.
+func (t *TypeRegistry) registerMembers(fqn api.FQN, overrides []api.Override) {
+	defer t.resetImplementations()
+
 	// Skipping registration if there are no members, as this would have no use.
-	if len(overrides) > 0 {
-		t.typeMembers[fqn] = make([]api.Override, len(overrides))
-		copy(t.typeMembers[fqn], overrides)
+	if len(overrides) == 0 {
+		return
 	}
 
-	return nil
+	t.typeMembers[fqn] = make([]api.Override, len(overrides))
+	copy(t.typeMembers[fqn], overrides)
+
+	byGoName := make(map[string]api.Override, len(overrides))
+	for _, override := range overrides {
+		if _, exists := byGoName[override.GoName()]; !exists {
+			byGoName[override.GoName()] = override
+		}
+	}
+	t.typeMembersByGoName[fqn] = byGoName
 }
 
 // RegisterEnum maps the given FQN to the provided enum type, and records the
@@ -105,11 +124,7 @@ func (t *TypeRegistry) RegisterInterface(fqn api.FQN, iface reflect.Type, overri
 	t.typeToInterfaceFQN[iface] = fqn
 	t.proxyMakers[iface] = maker
 
-	// Skipping registration if there are no members, as this would have no use.
-	if len(overrides) > 0 {
-		t.typeMembers[fqn] = make([]api.Override, len(overrides))
-		copy(t.typeMembers[fqn], overrides)
-	}
+	t.registerMembers(fqn, overrides)
 
 	return nil
 }
@@ -3,6 +3,7 @@ package typeregistry
 import (
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/aws/jsii-runtime-go/internal/api"
 )
@@ -39,6 +40,18 @@ type TypeRegistry struct {
 	// typeMembers maps each class or interface FQN to the set of members it
 	// implements in the form of api.Override values.
 	typeMembers map[api.FQN][]api.Override
+
+	// typeMembersByGoName indexes typeMembers by the go name of each member.
+	typeMembersByGoName map[api.FQN]map[string]api.Override
+
+	// implementations caches the result of DiscoverImplementation for each go
+	// type. It is reset whenever a class or interface is registered, as this
+	// may change the result.
+	implementations      map[reflect.Type]implementation
+	implementationsMutex sync.RWMutex
+	// implementationsGeneration is incremented whenever implementations is
+	// reset, so that results computed in the meantime are not cached.
+	implementationsGeneration uint64
 }
 
 type anonymousProxy struct{ _ int } // Padded so it's not 0-sized
@@ -46,13 +59,15 @@ type anonymousProxy struct{ _ int } // Padded so it's not 0-sized
 // New creates a new type registry.
 func New() *TypeRegistry {
 	registry := TypeRegistry{
-		fqnToType:          make(map[api.FQN]registeredType),
-		fqnToEnumMember:    make(map[string]interface{}),
-		typeToEnumFQN:      make(map[reflect.Type]api.FQN),
-		typeToInterfaceFQN: make(map[reflect.Type]api.FQN),
-		structInfo:         make(map[reflect.Type]registeredStruct),
-		proxyMakers:        make(map[reflect.Type]func() interface{}),
-		typeMembers:        make(map[api.FQN][]api.Override),
+		fqnToType:           make(map[api.FQN]registeredType),
+		fqnToEnumMember:     make(map[string]interface{}),
+		typeToEnumFQN:       make(map[reflect.Type]api.FQN),
+		typeToInterfaceFQN:  make(map[reflect.Type]api.FQN),
+		structInfo:          make(map[reflect.Type]registeredStruct),
+		proxyMakers:         make(map[reflect.Type]func() interface{}),
+		typeMembers:         make(map[api.FQN][]api.Override),
+		typeMembersByGoName: make(map[api.FQN]map[string]api.Override),
+		implementations:     make(map[reflect.Type]implementation),
 	}
 
 	// Ensure we can initialize proxies for `interface{}` when a method returns `any`.
@@ -169,6 +184,13 @@ func (t *TypeRegistry) TryRenderEnumRef(value reflect.Value) (ref *api.EnumRef,
 	return
 }
 
+// EnumFQN returns the jsii fully qualified name of the provided go enum type,
+// and a boolean telling whether the type is a registered enum type.
+func (t *TypeRegistry) EnumFQN(typ reflect.Type) (fqn api.FQN, found bool) {
+	fqn, found = t.typeToEnumFQN[typ]
+	return
+}
+
 func (t *TypeRegistry) InterfaceFQN(typ reflect.Type) (fqn api.FQN, found bool) {
 	fqn, found = t.typeToInterfaceFQN[typ]
 	return
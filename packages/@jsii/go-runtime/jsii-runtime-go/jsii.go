@@ -1,6 +1,13 @@
 package jsii
 
-import "github.com/aws/jsii-runtime-go/internal/kernel"
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/aws/jsii-runtime-go/internal/kernel/process"
+)
 
 // Close finalizes the runtime process, signalling the end of the execution to
 // the jsii kernel process, and waiting for graceful termination. The best
@@ -8,3 +15,48 @@ import "github.com/aws/jsii-runtime-go/internal/kernel"
 func Close() {
 	kernel.CloseClient()
 }
+
+// CloseStage identifies the step of closing the jsii kernel process that caused
+// it to exit, as reported by CloseWithTimeout and CloseContext.
+type CloseStage = kernel.CloseStage
+
+const (
+	// CloseStageNone means the jsii kernel process was not running.
+	CloseStageNone = process.CloseStageNone
+	// CloseStageExit means the jsii kernel process exited gracefully.
+	CloseStageExit = process.CloseStageExit
+	// CloseStageTerminate means the jsii kernel process exited once it was
+	// sent SIGTERM.
+	CloseStageTerminate = process.CloseStageTerminate
+	// CloseStageKill means the jsii kernel process had to be killed.
+	CloseStageKill = process.CloseStageKill
+)
+
+// CloseWithTimeout is like Close, but gives the jsii kernel process up to the
+// provided timeout to exit gracefully. If it does not, it is sent SIGTERM (on
+// platforms that support it), then killed. It returns the stage that caused
+// the process to exit, and an error if it did not exit at all. It is safe to
+// call while calls into jsii libraries are in progress on other goroutines,
+// which then fail.
+func CloseWithTimeout(timeout time.Duration) (CloseStage, error) {
+	return kernel.CloseClientWithTimeout(timeout)
+}
+
+// CloseContext is like CloseWithTimeout, but the jsii kernel process is given
+// until the provided context is done to exit gracefully.
+func CloseContext(ctx context.Context) (CloseStage, error) {
+	return kernel.CloseClientContext(ctx)
+}
+
+// HandleSignals makes sure the jsii kernel process and its temporary files are
+// cleaned up if this program receives one of the provided signals (os.Interrupt
+// and syscall.SIGTERM if none are provided), even if Close was not deferred or
+// could not run. The kernel process is given up to timeout (5 seconds if zero)
+// to terminate, after which it is killed. The signal is then raised again, so
+// the program terminates as it would have otherwise. HandleSignals does not
+// exit the program itself: if the signal is also handled elsewhere (e.g: using
+// signal.Notify), terminating the program is up to that handler. The returned
+// function stops handling the signals.
+func HandleSignals(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	return kernel.HandleSignals(timeout, signals...)
+}
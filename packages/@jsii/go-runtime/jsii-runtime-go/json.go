@@ -0,0 +1,38 @@
+package jsii
+
+import (
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+)
+
+// MarshalStruct returns the JSON encoding of the provided jsii struct value (or
+// pointer to one), in the same shape as the jsii kernel sees it: fields are
+// named after the jsii property names, nested structs are encoded as plain
+// objects, enum values as their member name, and dates as RFC3339 strings.
+//
+// Returns an error if the value is not a registered jsii struct, if a required
+// field is nil, or if the struct contains object references (which cannot be
+// serialized).
+func MarshalStruct(v interface{}) ([]byte, error) {
+	return kernel.GetClient().MarshalStruct(v)
+}
+
+// UnmarshalStruct decodes the provided JSON document (in the format produced
+// by MarshalStruct) into the jsii struct pointed to by v. This makes it
+// possible to load struct values (such as construct props) from configuration
+// files. Documents in other formats (e.g: YAML) can be loaded after they have
+// been converted to JSON.
+//
+// Returns an error if v is not a pointer to a registered jsii struct, or if
+// the document has properties that do not exist in the struct, lacks required
+// properties, or has values of the wrong type.
+func UnmarshalStruct(data []byte, v interface{}) error {
+	return kernel.GetClient().UnmarshalStruct(data, v)
+}
+
+// JSON is a JSON object value, corresponding to the jsii "json" primitive type.
+// Unlike other go maps, which are exchanged with the jsii kernel as jsii maps
+// (with their values converted to jsii references as needed), JSON values are
+// passed through verbatim, as other jsii language runtimes do. Values of the
+// "json" type received from the kernel can also be decoded into JSON.
+type JSON = api.JSON
@@ -0,0 +1,148 @@
+package jsii
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEnum string
+
+const (
+	mockEnum_ALPHA mockEnum = "ALPHA"
+	mockEnum_BETA  mockEnum = "BETA"
+)
+
+type MockNestedProps struct {
+	Label *string    `field:"required" json:"label" yaml:"label"`
+	Kind  mockEnum   `field:"optional" json:"kind" yaml:"kind"`
+	When  *time.Time `field:"optional" json:"when" yaml:"when"`
+}
+
+type MockProps struct {
+	Name     *string             `field:"required" json:"name" yaml:"name"`
+	Count    *float64            `field:"optional" json:"count" yaml:"count"`
+	Enabled  *bool               `field:"optional" json:"enabled" yaml:"enabled"`
+	Nested   *MockNestedProps    `field:"optional" json:"nested" yaml:"nested"`
+	List     *[]*MockNestedProps `field:"optional" json:"list" yaml:"list"`
+	Lookup   *map[string]*string `field:"optional" json:"lookup" yaml:"lookup"`
+	Anything interface{}         `field:"optional" json:"anything" yaml:"anything"`
+}
+
+func registerStructMocks(t *testing.T) {
+	registry := kernel.GetClient().Types()
+	if err := registry.RegisterEnum("mock.Enum", reflect.TypeOf(mockEnum("")), map[string]interface{}{"ALPHA": mockEnum_ALPHA, "BETA": mockEnum_BETA}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterStruct("mock.NestedProps", reflect.TypeOf(MockNestedProps{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterStruct("mock.Props", reflect.TypeOf(MockProps{})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarshalStructRoundTrip(t *testing.T) {
+	registerStructMocks(t)
+
+	when := time.Date(2021, time.March, 14, 15, 9, 26, 535000000, time.UTC)
+	props := MockProps{
+		Name:     String("root"),
+		Count:    Number(42),
+		Enabled:  Bool(true),
+		Nested:   &MockNestedProps{Label: String("nested"), Kind: mockEnum_BETA, When: &when},
+		List:     &[]*MockNestedProps{{Label: String("first")}, {Label: String("second"), Kind: mockEnum_ALPHA}},
+		Lookup:   &map[string]*string{"key": String("value")},
+		Anything: map[string]interface{}{"free": "form"},
+	}
+
+	data, err := MarshalStruct(&props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+		"name": "root",
+		"count": 42,
+		"enabled": true,
+		"nested": { "label": "nested", "kind": "BETA", "when": "2021-03-14T15:09:26.535Z" },
+		"list": [{ "label": "first" }, { "label": "second", "kind": "ALPHA" }],
+		"lookup": { "key": "value" },
+		"anything": { "free": "form" }
+	}`, string(data))
+
+	var decoded MockProps
+	if err := UnmarshalStruct(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, props, decoded)
+}
+
+func TestUnmarshalStructErrors(t *testing.T) {
+	registerStructMocks(t)
+
+	for name, document := range map[string]string{
+		"missing required field": `{ "count": 1 }`,
+		"unknown property":       `{ "name": "root", "colour": "blue" }`,
+		"wrong type":             `{ "name": 1337 }`,
+		"invalid enum member":    `{ "name": "root", "nested": { "label": "nested", "kind": "GAMMA" } }`,
+		"invalid date":           `{ "name": "root", "nested": { "label": "nested", "when": "yesterday" } }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var props MockProps
+			assert.Error(t, UnmarshalStruct([]byte(document), &props))
+		})
+	}
+}
+
+func TestMarshalStructRequiresFields(t *testing.T) {
+	registerStructMocks(t)
+
+	_, err := MarshalStruct(MockProps{Nested: &MockNestedProps{}})
+	assert.Error(t, err)
+}
+
+func TestStructJSONNilValues(t *testing.T) {
+	registerStructMocks(t)
+
+	_, err := MarshalStruct(nil)
+	assert.Error(t, err)
+	_, err = MarshalStruct((*MockProps)(nil))
+	assert.Error(t, err)
+
+	assert.Error(t, UnmarshalStruct([]byte(`{ "name": "root" }`), nil))
+	assert.Error(t, UnmarshalStruct([]byte(`{ "name": "root" }`), (*MockProps)(nil)))
+}
+
+type MockCounterProps struct {
+	Total *int64  `field:"required" json:"total" yaml:"total"`
+	Step  *uint32 `field:"optional" json:"step" yaml:"step"`
+}
+
+func TestMarshalStructIntegers(t *testing.T) {
+	if err := kernel.GetClient().Types().RegisterStruct("mock.CounterProps", reflect.TypeOf(MockCounterProps{})); err != nil {
+		t.Fatal(err)
+	}
+
+	total, step := int64(1<<53-1), uint32(7)
+	data, err := MarshalStruct(MockCounterProps{Total: &total, Step: &step})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{ "total": 9007199254740991, "step": 7 }`, string(data))
+
+	var decoded MockCounterProps
+	if err := UnmarshalStruct(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, MockCounterProps{Total: &total, Step: &step}, decoded)
+
+	assert.Error(t, UnmarshalStruct([]byte(`{ "total": 1.5 }`), &decoded), "non-integral value")
+	assert.Error(t, UnmarshalStruct([]byte(`{ "total": 1, "step": -1 }`), &decoded), "out of range value")
+
+	total = 1 << 53
+	_, err = MarshalStruct(MockCounterProps{Total: &total})
+	assert.Error(t, err, "value cannot be represented exactly")
+}
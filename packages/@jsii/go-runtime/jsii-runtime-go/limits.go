@@ -0,0 +1,33 @@
+package jsii
+
+import "github.com/aws/jsii-runtime-go/internal/kernel"
+
+// CallbackLimits bounds the fulfillment of callbacks from the jsii kernel (such
+// as calls to method overrides), so that runaway recursion between JavaScript
+// and go code, or go code that blocks forever, fail with a diagnostic error
+// instead of crashing or hanging the process. The defaults can be set using the
+// JSII_CALLBACK_MAX_DEPTH and JSII_CALLBACK_TIMEOUT environment variables.
+type CallbackLimits = kernel.CallbackLimits
+
+// CallbackFrame describes a pending callback in the chain reported by a
+// CallbackDepthError or CallbackTimeoutError.
+type CallbackFrame = kernel.CallbackFrame
+
+// CallbackDepthError is the cause of the JavaScriptError raised when callbacks
+// are nested deeper than CallbackLimits.MaxDepth.
+type CallbackDepthError = kernel.CallbackDepthError
+
+// CallbackTimeoutError is the cause of the JavaScriptError raised when a
+// callback takes longer than CallbackLimits.Timeout to complete.
+type CallbackTimeoutError = kernel.CallbackTimeoutError
+
+// GetCallbackLimits returns the limits currently applied to callbacks.
+func GetCallbackLimits() CallbackLimits {
+	return kernel.GetCallbackLimits()
+}
+
+// SetCallbackLimits changes the limits applied to callbacks, and returns the
+// previous ones.
+func SetCallbackLimits(limits CallbackLimits) CallbackLimits {
+	return kernel.SetCallbackLimits(limits)
+}
@@ -0,0 +1,54 @@
+package jsii
+
+import "github.com/aws/jsii-runtime-go/internal/kernel"
+
+// MemoryUsage describes the memory used by the jsii kernel process: its
+// resident set size, the node heap figures reported by the kernel, and the
+// number of kernel objects (in total, and referenced from go).
+type MemoryUsage = kernel.MemoryUsage
+
+// RecyclePolicy sets thresholds on the memory used by the jsii kernel process,
+// above which it is replaced by a new one, so that long-running programs do not
+// exhaust the node heap. This only happens when SafePoint is called, as the
+// runtime cannot tell when jsii objects are no longer used. Libraries are loaded
+// again into the new process. The default policy can be set using the
+// JSII_RECYCLE_MAX_RSS and JSII_RECYCLE_MAX_HEAP environment variables (in
+// mebibytes).
+type RecyclePolicy = kernel.RecyclePolicy
+
+// Memory samples the memory used by the jsii kernel process, starting it if
+// that has not happened yet. This panics if the kernel process cannot be
+// started.
+func Memory() MemoryUsage {
+	usage, err := kernel.GetClient().MemoryUsage()
+	if err != nil {
+		panic(err)
+	}
+	return usage
+}
+
+// GetRecyclePolicy returns the policy currently applied to the jsii kernel
+// process.
+func GetRecyclePolicy() RecyclePolicy {
+	return kernel.GetRecyclePolicy()
+}
+
+// SetRecyclePolicy changes the policy applied to the jsii kernel process, and
+// returns the previous one.
+func SetRecyclePolicy(policy RecyclePolicy) RecyclePolicy {
+	return kernel.SetRecyclePolicy(policy)
+}
+
+// SafePoint declares that none of the jsii objects obtained so far will be used
+// anymore (e.g: between two units of work of a long-running service). If the
+// memory used by the jsii kernel process crosses the thresholds of the
+// RecyclePolicy, the process is recycled, which makes all these objects
+// unusable. It returns whether the process was recycled, and panics if it could
+// not be.
+func SafePoint() bool {
+	recycled, err := kernel.GetClient().SafePoint()
+	if err != nil {
+		panic(err)
+	}
+	return recycled
+}
@@ -0,0 +1,224 @@
+package protocol
+
+import (
+	"fmt"
+	"io"
+)
+
+// CallbackHandler fulfills a callback sent by the kernel while it processes a
+// request, and returns its outcome.
+type CallbackHandler func(Callback) CallbackResult
+
+// Transport exchanges messages with the kernel. Conn is a Transport; others
+// may wrap one, for instance to manage the kernel process it is connected to.
+type Transport interface {
+	// Send encodes the provided message and writes it.
+	Send(message interface{}) error
+	// Receive reads the next message, and decodes it into the provided value.
+	Receive(into interface{}) error
+}
+
+// Client speaks the kernel protocol over a Transport. It sends one request at a
+// time, and fulfills the callbacks the kernel sends while processing it using
+// its CallbackHandler. Callbacks may send requests of their own using the same
+// Client, as the kernel processes these while it waits. A Client is not safe
+// for concurrent use by several goroutines.
+type Client struct {
+	transport Transport
+	handler   CallbackHandler
+
+	// The kernel's Hello message and the features it supports, once received.
+	hello    Hello
+	features FeatureSet
+}
+
+// NewClient returns a Client that speaks the protocol over the provided
+// stream. Callbacks are fulfilled by handler, which may be nil if no callbacks
+// are expected, in which case they fail.
+func NewClient(stream io.ReadWriter, handler CallbackHandler) *Client {
+	return &Client{transport: NewConn(stream), handler: handler}
+}
+
+// Attach returns a Client that speaks the protocol over the provided transport,
+// with a kernel whose Hello message was already received (e.g: by the code that
+// started the kernel process). Callbacks are fulfilled by handler, as with
+// NewClient.
+func Attach(transport Transport, hello Hello, handler CallbackHandler) *Client {
+	return &Client{transport: transport, handler: handler, hello: hello, features: hello.SupportedFeatures()}
+}
+
+// WithHandler returns a copy of the Client that fulfills callbacks using the
+// provided handler. Both speak the protocol over the same transport.
+func (c *Client) WithHandler(handler CallbackHandler) *Client {
+	clone := *c
+	clone.handler = handler
+	return &clone
+}
+
+// Hello reads the handshake message the kernel sends when it starts. It must
+// be called before any request is sent, unless the Client was attached.
+func (c *Client) Hello() (hello Hello, err error) {
+	if err = c.transport.Receive(&hello); err == nil {
+		c.hello, c.features = hello, hello.SupportedFeatures()
+	}
+	return
+}
+
+// Features returns the features supported by the kernel, or nil if its Hello
+// message has not been received yet.
+func (c *Client) Features() FeatureSet {
+	return c.features
+}
+
+// Prepare verifies that the request can be sent: it must be valid, and belong
+// to a feature the kernel supports (if its Hello message was received), or a
+// *MissingFeatureError is returned.
+func (c *Client) Prepare(request Request) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	if c.features != nil {
+		if err := c.features.Check(c.hello.Hello, request.API); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Request prepares and sends the request (see Prepare), and decodes the
+// kernel's response into result (see Resume).
+func (c *Client) Request(request Request, result Result) error {
+	if err := c.Prepare(request); err != nil {
+		return err
+	}
+	if err := c.transport.Send(request); err != nil {
+		return err
+	}
+
+	env := NewResponse(result)
+	if err := c.transport.Receive(&env); err != nil {
+		return err
+	}
+	return c.Resume(&env, result)
+}
+
+// Resume processes a response to a request that was sent by other means (e.g:
+// pipelined with other requests): the callbacks the kernel sends in lieu of the
+// final response are fulfilled, until that is received and decoded into
+// result. If the kernel responds with an error, it is returned as an *Error.
+func (c *Client) Resume(env *Response, result Result) error {
+	for env.Callback != nil {
+		if err := c.transport.Send(CallbackCompletion{c.fulfill(*env.Callback)}); err != nil {
+			return err
+		}
+		*env = NewResponse(result)
+		if err := c.transport.Receive(env); err != nil {
+			return err
+		}
+	}
+	if err := env.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// fulfill executes the callback using the handler.
+func (c *Client) fulfill(cb Callback) CallbackResult {
+	if err := cb.Validate(); err != nil {
+		return CallbackResult{CallbackID: cb.CallbackID, Error: fmt.Sprintf("invalid callback: %v", err)}
+	}
+	if c.handler == nil {
+		return CallbackResult{CallbackID: cb.CallbackID, Error: "this client does not handle callbacks"}
+	}
+	return c.handler(cb)
+}
+
+// Exit requests the kernel to terminate with the provided exit code.
+func (c *Client) Exit(code int) error {
+	return c.transport.Send(Exit{Code: code})
+}
+
+func (c *Client) Load(props LoadProps) (response LoadResponse, err error) {
+	err = c.Request(NewRequest("load", props), &response)
+	return
+}
+
+func (c *Client) GetScriptCommand(props GetScriptCommandProps) (response GetScriptCommandResponse, err error) {
+	err = c.Request(NewRequest("getBinScriptCommand", props), &response)
+	return
+}
+
+func (c *Client) InvokeScript(props InvokeScriptProps) (response InvokeScriptResponse, err error) {
+	err = c.Request(NewRequest("invokeBinScript", props), &response)
+	return
+}
+
+func (c *Client) Naming(props NamingProps) (response NamingResponse, err error) {
+	err = c.Request(NewRequest("naming", props), &response)
+	return
+}
+
+func (c *Client) Stats() (response StatsResponse, err error) {
+	err = c.Request(NewRequest("stats", nil), &response)
+	return
+}
+
+func (c *Client) Create(props CreateProps) (response CreateResponse, err error) {
+	err = c.Request(NewRequest("create", props), &response)
+	return
+}
+
+func (c *Client) Del(props DelProps) (response DelResponse, err error) {
+	err = c.Request(NewRequest("del", props), &response)
+	return
+}
+
+func (c *Client) Invoke(props InvokeProps) (response InvokeResponse, err error) {
+	err = c.Request(NewRequest("invoke", props), &response)
+	return
+}
+
+func (c *Client) SInvoke(props StaticInvokeProps) (response InvokeResponse, err error) {
+	err = c.Request(NewRequest("sinvoke", props), &response)
+	return
+}
+
+func (c *Client) Get(props GetProps) (response GetResponse, err error) {
+	err = c.Request(NewRequest("get", props), &response)
+	return
+}
+
+func (c *Client) SGet(props StaticGetProps) (response GetResponse, err error) {
+	err = c.Request(NewRequest("sget", props), &response)
+	return
+}
+
+func (c *Client) Set(props SetProps) (response SetResponse, err error) {
+	err = c.Request(NewRequest("set", props), &response)
+	return
+}
+
+func (c *Client) SSet(props StaticSetProps) (response SetResponse, err error) {
+	err = c.Request(NewRequest("sset", props), &response)
+	return
+}
+
+func (c *Client) Begin(props BeginProps) (response BeginResponse, err error) {
+	err = c.Request(NewRequest("begin", props), &response)
+	return
+}
+
+func (c *Client) End(props EndProps) (response EndResponse, err error) {
+	err = c.Request(NewRequest("end", props), &response)
+	return
+}
+
+func (c *Client) Callbacks() (response CallbacksResponse, err error) {
+	err = c.Request(NewRequest("callbacks", nil), &response)
+	return
+}
+
+func (c *Client) Complete(props CompleteProps) (response CompleteResponse, err error) {
+	err = c.Request(NewRequest("complete", props), &response)
+	return
+}
@@ -0,0 +1,131 @@
+package protocol
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+)
+
+// serveFakeKernel emulates the kernel on the provided connection: it greets the
+// client, then responds to invoke requests on "test.Greeter" objects by
+// requesting a callback for the "name" property, and greeting it.
+func serveFakeKernel(t *testing.T, stream io.ReadWriter) {
+	conn := NewConn(stream)
+	if err := conn.Send(Hello{Hello: "@jsii/runtime@1.2.3"}); err != nil {
+		t.Error(err)
+		return
+	}
+	for {
+		var request Request
+		if err := conn.Receive(&request); err != nil {
+			return
+		}
+		props, ok := request.Props.(*InvokeProps)
+		if !ok || props.ObjRef.TypeFQN() != "test.Greeter" {
+			conn.Send(Response{Error: stringPtr("unsupported request"), Name: stringPtr(FaultErrorName)})
+			continue
+		}
+
+		conn.Send(Response{Callback: &Callback{CallbackID: "jsii::callback::1", Cookie: "Name", Get: &GetCallback{Property: "name", ObjRef: props.ObjRef}}})
+		var completion CallbackCompletion
+		if err := conn.Receive(&completion); err != nil {
+			t.Error(err)
+			return
+		}
+		if completion.Error != "" {
+			conn.Send(Response{Error: stringPtr(completion.Error)})
+			continue
+		}
+		conn.Send(Response{Ok: InvokeResponse{Result: "Hello, " + completion.Result.(string)}})
+	}
+}
+
+func stringPtr(s string) *string { return &s }
+
+func TestClient(t *testing.T) {
+	clientSide, kernelSide := net.Pipe()
+	defer clientSide.Close()
+	go func() {
+		defer kernelSide.Close()
+		serveFakeKernel(t, kernelSide)
+	}()
+
+	var callbacks []Callback
+	client := NewClient(clientSide, func(cb Callback) CallbackResult {
+		callbacks = append(callbacks, cb)
+		return CallbackResult{CallbackID: cb.CallbackID, Result: "World"}
+	})
+
+	hello, err := client.Hello()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := hello.RuntimeVersion(); err != nil || version.String() != "1.2.3" {
+		t.Errorf("expected version 1.2.3, got %v (%v)", version, err)
+	}
+
+	greeter := ObjectRef{InstanceID: "test.Greeter@10000"}
+	if res, err := client.Invoke(InvokeProps{Method: "greet", ObjRef: greeter}); err != nil {
+		t.Fatal(err)
+	} else if res.Result != "Hello, World" {
+		t.Errorf("expected Hello, World, got %#v", res.Result)
+	}
+	if len(callbacks) != 1 || callbacks[0].Get == nil || callbacks[0].Get.Property != "name" {
+		t.Errorf("expected a callback for the name property, got %#v", callbacks)
+	}
+
+	_, err = client.SInvoke(StaticInvokeProps{FQN: "test.Other", Method: "greet"})
+	if kernelErr, ok := err.(*Error); !ok || !kernelErr.IsFault() || kernelErr.Message != "unsupported request" {
+		t.Errorf("expected a fault, got %#v", err)
+	}
+
+	if _, err := client.Invoke(InvokeProps{Method: "greet"}); err == nil {
+		t.Error("expected invalid requests to be rejected")
+	}
+
+	var raw Response
+	encoded, _ := json.Marshal(Response{Ok: CreateResponse{InstanceID: "test.Greeter@10001"}})
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		t.Fatal(err)
+	} else if payload, _ := raw.Ok.(map[string]interface{}); payload["$jsii.byref"] != "test.Greeter@10001" {
+		t.Errorf("expected the payload to be decoded as a map when no result is set, got %#v", raw.Ok)
+	}
+}
+
+func TestAttachedClient(t *testing.T) {
+	clientSide, kernelSide := net.Pipe()
+	defer clientSide.Close()
+	go func() {
+		defer kernelSide.Close()
+		serveFakeKernel(t, kernelSide)
+	}()
+
+	// The handshake is received by other means, as when starting the kernel.
+	conn := NewConn(clientSide)
+	var hello Hello
+	if err := conn.Receive(&hello); err != nil {
+		t.Fatal(err)
+	}
+	client := Attach(conn, hello, nil)
+	if !client.Features().Has(FeatureAsync) {
+		t.Errorf("expected the baseline features, got %v", client.Features())
+	}
+
+	// Each request may handle its callbacks differently.
+	greeter := ObjectRef{InstanceID: "test.Greeter@10000"}
+	for _, name := range []string{"Alice", "Bob"} {
+		name := name
+		greeting := client.WithHandler(func(cb Callback) CallbackResult {
+			return CallbackResult{CallbackID: cb.CallbackID, Result: name}
+		})
+		if res, err := greeting.Invoke(InvokeProps{Method: "greet", ObjRef: greeter}); err != nil {
+			t.Fatal(err)
+		} else if res.Result != "Hello, "+name {
+			t.Errorf("expected Hello, %v, got %#v", name, res.Result)
+		}
+	}
+	if _, err := client.Invoke(InvokeProps{Method: "greet", ObjRef: greeter}); err == nil || err.Error() != "this client does not handle callbacks" {
+		t.Errorf("expected the callback to fail without a handler, got %v", err)
+	}
+}
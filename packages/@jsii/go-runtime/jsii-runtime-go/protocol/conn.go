@@ -0,0 +1,56 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Conn exchanges newline-delimited JSON messages with the kernel (or with a
+// client of the kernel, for tools that emulate it). It performs no
+// synchronization: concurrent use must be guarded by the caller.
+type Conn struct {
+	encoder *json.Encoder
+	decoder *json.Decoder
+	tracer  *tracer
+}
+
+// NewConn returns a Conn that writes messages to, and reads messages from, the
+// provided stream.
+func NewConn(stream io.ReadWriter) *Conn {
+	return NewSplitConn(stream, stream)
+}
+
+// NewSplitConn returns a Conn that reads messages from r, and writes messages
+// to w, such as the standard output and input of the kernel process.
+func NewSplitConn(r io.Reader, w io.Writer) *Conn {
+	tracer := &tracer{}
+	decoder := json.NewDecoder(tracingReader{r, tracer})
+	// Numbers are decoded as json.Number, so that integers that cannot be
+	// represented exactly as a float64 are not silently rounded.
+	decoder.UseNumber()
+	return &Conn{encoder: json.NewEncoder(tracingWriter{w, tracer}), decoder: decoder, tracer: tracer}
+}
+
+// SetTrace makes the Conn write a copy of each message it sends or receives to
+// out, one per line, prefixed with SentPrefix or ReceivedPrefix respectively.
+// Passing nil stops tracing. Messages are traced as they are written to, or
+// read from the stream, which may happen ahead of their decoding.
+func (c *Conn) SetTrace(out io.Writer) {
+	c.tracer.set(out)
+}
+
+// Send encodes the provided message (e.g: a Request) and writes it.
+func (c *Conn) Send(message interface{}) error {
+	return c.encoder.Encode(message)
+}
+
+// Receive reads the next message, and decodes it into the provided value (e.g:
+// a *Response), in a single pass. Interpreting the message (e.g: to identify
+// error responses) is the caller's responsibility.
+func (c *Conn) Receive(into interface{}) error {
+	if !c.decoder.More() {
+		return fmt.Errorf("no message received: %w", io.EOF)
+	}
+	return c.decoder.Decode(into)
+}
@@ -0,0 +1,15 @@
+// Package protocol describes the wire format used to communicate with the
+// `@jsii/kernel` process: the values exchanged (object references, dates,
+// maps, structs, ...), the requests and responses of each kernel API, and the
+// callbacks the kernel may send while it processes a request. It also provides
+// JSON codecs and validation for these, and a Client that speaks the protocol
+// over any io.ReadWriter (or Transport).
+//
+// This package is used by the jsii runtime for go itself, and can be used by
+// tools that need to communicate with the `@jsii/kernel` process directly.
+package protocol
+
+// Version is the revision of the wire format described by this package. It is
+// incremented whenever messages are changed in ways that are not backwards
+// compatible, so that tools exchanging them can detect mismatches.
+const Version = 1
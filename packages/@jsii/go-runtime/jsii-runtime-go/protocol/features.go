@@ -0,0 +1,132 @@
+package protocol
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Feature names an optional capability of the kernel, typically a group of
+// APIs that not all kernels implement.
+type Feature string
+
+const (
+	// FeatureAsync is support for async methods: the begin, end, callbacks and
+	// complete APIs.
+	FeatureAsync Feature = "async"
+	// FeatureNaming is support for the naming API.
+	FeatureNaming Feature = "naming"
+	// FeatureStats is support for the stats API.
+	FeatureStats Feature = "stats"
+	// FeatureScripts is support for running the scripts of libraries: the
+	// getBinScriptCommand and invokeBinScript APIs.
+	FeatureScripts Feature = "scripts"
+)
+
+// BaselineFeatures are the features assumed to be supported by kernels that do
+// not advertise any in their Hello message, which is the case of all kernels
+// this revision of the protocol was designed against.
+var BaselineFeatures = []Feature{FeatureAsync, FeatureNaming, FeatureScripts, FeatureStats}
+
+// featureAPIs maps APIs to the feature they are part of. APIs that are not
+// listed are supported by all kernels.
+var featureAPIs = map[string]Feature{
+	"begin":     FeatureAsync,
+	"end":       FeatureAsync,
+	"callbacks": FeatureAsync,
+	"complete":  FeatureAsync,
+	"naming":    FeatureNaming,
+	"stats":     FeatureStats,
+
+	"getBinScriptCommand": FeatureScripts,
+	"invokeBinScript":     FeatureScripts,
+}
+
+// RequiredFeature returns the feature the kernel must support for the provided
+// API to be used, if any.
+func RequiredFeature(api string) (feature Feature, required bool) {
+	feature, required = featureAPIs[api]
+	return
+}
+
+// FeatureSet is a set of features, as negotiated with a kernel.
+type FeatureSet map[Feature]bool
+
+// Has returns true if the set contains the provided feature.
+func (s FeatureSet) Has(feature Feature) bool {
+	return s[feature]
+}
+
+// Sorted returns the features in the set, sorted by name.
+func (s FeatureSet) Sorted() []Feature {
+	features := make([]Feature, 0, len(s))
+	for feature := range s {
+		features = append(features, feature)
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features
+}
+
+// SupportedFeatures returns the features the kernel advertised, or
+// BaselineFeatures if it did not advertise any.
+func (h *Hello) SupportedFeatures() FeatureSet {
+	features := h.Features
+	if features == nil {
+		features = BaselineFeatures
+	}
+	set := make(FeatureSet, len(features))
+	for _, feature := range features {
+		set[feature] = true
+	}
+	return set
+}
+
+// Negotiate determines the features supported by the kernel that sent the
+// provided Hello message. It returns a *MissingFeatureError naming any of the
+// required features that are not supported.
+func Negotiate(hello *Hello, required ...Feature) (FeatureSet, error) {
+	supported := hello.SupportedFeatures()
+
+	var missing []Feature
+	for _, feature := range required {
+		if !supported.Has(feature) {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) > 0 {
+		return supported, &MissingFeatureError{Runtime: hello.Hello, Features: missing}
+	}
+	return supported, nil
+}
+
+// Check returns a *MissingFeatureError if the provided API belongs to a feature
+// that is not in the set.
+func (s FeatureSet) Check(runtime string, api string) error {
+	if feature, required := RequiredFeature(api); required && !s.Has(feature) {
+		return &MissingFeatureError{Runtime: runtime, Features: []Feature{feature}, API: api}
+	}
+	return nil
+}
+
+// MissingFeatureError is returned when a feature that is needed is not
+// supported by the kernel.
+type MissingFeatureError struct {
+	// Runtime identifies the kernel, as in its Hello message.
+	Runtime string
+	// Features lists the missing features.
+	Features []Feature
+	// API is the API that could not be used, if any.
+	API string
+}
+
+func (e *MissingFeatureError) Error() string {
+	names := make([]string, len(e.Features))
+	for i, feature := range e.Features {
+		names[i] = string(feature)
+	}
+	msg := fmt.Sprintf("the kernel (%v) does not support the %v feature(s)", e.Runtime, strings.Join(names, ", "))
+	if e.API != "" {
+		msg = fmt.Sprintf("%v, which the %q API requires", msg, e.API)
+	}
+	return msg
+}
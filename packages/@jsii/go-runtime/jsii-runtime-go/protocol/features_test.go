@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	legacy := Hello{Hello: "@jsii/runtime@1.2.3"}
+	if features, err := Negotiate(&legacy, FeatureAsync, FeatureStats); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(features.Sorted(), []Feature{FeatureAsync, FeatureNaming, FeatureScripts, FeatureStats}) {
+		t.Errorf("expected the baseline features, got %v", features.Sorted())
+	}
+
+	advertising := Hello{Hello: "@jsii/runtime@1.2.3", Features: []Feature{FeatureAsync}}
+	_, err := Negotiate(&advertising, FeatureAsync, FeatureNaming, FeatureStats)
+	var missing *MissingFeatureError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a *MissingFeatureError, got %#v", err)
+	}
+	if !reflect.DeepEqual(missing.Features, []Feature{FeatureNaming, FeatureStats}) {
+		t.Errorf("expected naming and stats to be missing, got %v", missing.Features)
+	}
+	if expected := "the kernel (@jsii/runtime@1.2.3) does not support the naming, stats feature(s)"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestRuntimePackage(t *testing.T) {
+	for hello, expected := range map[string]string{"@jsii/runtime@1.2.3": "@jsii/runtime", "runtime@1.2.3": "runtime", "runtime": "runtime"} {
+		if actual := (&Hello{Hello: hello}).RuntimePackage(); actual != expected {
+			t.Errorf("expected %v for %v, got %v", expected, hello, actual)
+		}
+	}
+}
+
+func TestClientChecksFeatures(t *testing.T) {
+	clientSide, kernelSide := net.Pipe()
+	defer clientSide.Close()
+	go func() {
+		defer kernelSide.Close()
+		NewConn(kernelSide).Send(Hello{Hello: "@jsii/runtime@1.2.3", Features: []Feature{FeatureAsync}})
+	}()
+
+	client := NewClient(clientSide, nil)
+	if _, err := client.Hello(); err != nil {
+		t.Fatal(err)
+	}
+	if !client.Features().Has(FeatureAsync) || client.Features().Has(FeatureStats) {
+		t.Errorf("expected only the async feature, got %v", client.Features().Sorted())
+	}
+
+	_, err := client.Stats()
+	var missing *MissingFeatureError
+	if !errors.As(err, &missing) || missing.API != "stats" {
+		t.Fatalf("expected a *MissingFeatureError for stats, got %#v", err)
+	}
+}
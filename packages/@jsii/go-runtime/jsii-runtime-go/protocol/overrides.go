@@ -0,0 +1,91 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Override is a public interface implementing a private method `isOverride`
+// implemented by the private custom type `override`. This is embedded by
+// MethodOverride and PropertyOverride to simulate the union type of Override =
+// MethodOverride | PropertyOverride.
+type Override interface {
+	GoName() string
+	isOverride()
+}
+
+type override struct{}
+
+func (o override) isOverride() {}
+
+// MethodOverride is used to register a "go-native" implementation to be
+// substituted to the default javascript implementation on the created object.
+type MethodOverride struct {
+	override
+
+	JsiiMethod string `json:"method"`
+	GoMethod   string `json:"cookie"`
+}
+
+func (m MethodOverride) GoName() string {
+	return m.GoMethod
+}
+
+// PropertyOverride is used to register a "go-native" implementation to be
+// substituted to the default javascript implementation on the created object.
+type PropertyOverride struct {
+	override
+
+	JsiiProperty string `json:"property"`
+	GoGetter     string `json:"cookie"`
+}
+
+func (m PropertyOverride) GoName() string {
+	return m.GoGetter
+}
+
+func IsMethodOverride(value Override) bool {
+	switch value.(type) {
+	case MethodOverride, *MethodOverride:
+		return true
+	default:
+		return false
+	}
+}
+
+func IsPropertyOverride(value Override) bool {
+	switch value.(type) {
+	case PropertyOverride, *PropertyOverride:
+		return true
+	default:
+		return false
+	}
+}
+
+// Overrides is a list of overrides that can be decoded from JSON, as the
+// Override interface itself cannot be.
+type Overrides []Override
+
+func (o *Overrides) UnmarshalJSON(data []byte) error {
+	var raw []struct {
+		Method   *string `json:"method"`
+		Property *string `json:"property"`
+		Cookie   string  `json:"cookie"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*o = make(Overrides, len(raw))
+	for i, entry := range raw {
+		switch {
+		case entry.Method != nil && entry.Property == nil:
+			(*o)[i] = MethodOverride{JsiiMethod: *entry.Method, GoMethod: entry.Cookie}
+		case entry.Property != nil && entry.Method == nil:
+			(*o)[i] = PropertyOverride{JsiiProperty: *entry.Property, GoGetter: entry.Cookie}
+		default:
+			return fmt.Errorf("override #%d must have exactly one of method or property", i)
+		}
+	}
+	return nil
+}
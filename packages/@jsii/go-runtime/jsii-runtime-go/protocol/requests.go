@@ -0,0 +1,308 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Request is a request for the kernel to execute one of its APIs. It is
+// encoded as a JSON object that has the props' fields along with the "api"
+// field naming the API.
+type Request struct {
+	// API is the name of the kernel API (e.g: "invoke").
+	API string
+	// Props are the request's parameters (e.g: InvokeProps), which must encode
+	// as a JSON object. Requests that have no parameters have nil props.
+	Props interface{}
+}
+
+// NewRequest returns a request for the provided API, with the provided props.
+func NewRequest(api string, props interface{}) Request {
+	return Request{API: api, Props: props}
+}
+
+// propsTypes associates the name of each kernel API with the type of its
+// request's props, so requests can be decoded into the appropriate type. APIs
+// that have no parameters are associated with nil.
+var propsTypes = map[string]reflect.Type{
+	"load":                reflect.TypeOf(LoadProps{}),
+	"getBinScriptCommand": reflect.TypeOf(GetScriptCommandProps{}),
+	"invokeBinScript":     reflect.TypeOf(InvokeScriptProps{}),
+	"naming":              reflect.TypeOf(NamingProps{}),
+	"stats":               nil,
+	"create":              reflect.TypeOf(CreateProps{}),
+	"del":                 reflect.TypeOf(DelProps{}),
+	"invoke":              reflect.TypeOf(InvokeProps{}),
+	"sinvoke":             reflect.TypeOf(StaticInvokeProps{}),
+	"get":                 reflect.TypeOf(GetProps{}),
+	"sget":                reflect.TypeOf(StaticGetProps{}),
+	"set":                 reflect.TypeOf(SetProps{}),
+	"sset":                reflect.TypeOf(StaticSetProps{}),
+	"begin":               reflect.TypeOf(BeginProps{}),
+	"end":                 reflect.TypeOf(EndProps{}),
+	"callbacks":           nil,
+	"complete":            reflect.TypeOf(CompleteProps{}),
+}
+
+// IsKnownAPI returns true if the named API is described by this package.
+func IsKnownAPI(api string) bool {
+	_, found := propsTypes[api]
+	return found
+}
+
+func (r Request) MarshalJSON() ([]byte, error) {
+	api, err := json.Marshal(r.API)
+	if err != nil {
+		return nil, err
+	}
+	if r.Props == nil {
+		return []byte(fmt.Sprintf(`{"api":%s}`, api)), nil
+	}
+
+	props, err := json.Marshal(r.Props)
+	if err != nil {
+		return nil, err
+	}
+	props = bytes.TrimSpace(props)
+	if len(props) < 2 || props[0] != '{' {
+		return nil, fmt.Errorf("the props of a %v request must encode as a JSON object, not %s", r.API, props)
+	}
+	if bytes.Equal(props, []byte("{}")) {
+		return []byte(fmt.Sprintf(`{"api":%s}`, api)), nil
+	}
+	return []byte(fmt.Sprintf(`{"api":%s,%s`, api, props[1:])), nil
+}
+
+// UnmarshalJSON decodes a request, with its props decoded into the type that
+// corresponds to its API (e.g: *InvokeProps for "invoke"). The props of APIs
+// that are not known to this package are decoded as a map. Numbers are decoded
+// as json.Number, so they are not rounded.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	var head struct {
+		API *string `json:"api"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return err
+	}
+	if head.API == nil {
+		return fmt.Errorf("not a kernel API request: %s", data)
+	}
+
+	r.API = *head.API
+	typ, known := propsTypes[r.API]
+	switch {
+	case known && typ == nil:
+		r.Props = nil
+		return nil
+	case known:
+		r.Props = reflect.New(typ).Interface()
+	default:
+		r.Props = &map[string]interface{}{}
+	}
+
+	if err := decode(data, r.Props); err != nil {
+		return fmt.Errorf("invalid %v request: %w", r.API, err)
+	}
+	if props, ok := r.Props.(*map[string]interface{}); ok {
+		delete(*props, "api")
+		r.Props = *props
+	}
+	return nil
+}
+
+// decode decodes data into the provided value, with numbers decoded as
+// json.Number.
+func decode(data []byte, into interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(into)
+}
+
+// LoadProps holds the necessary information to load a library into the
+// kernel.
+type LoadProps struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Tarball is the path to the library's npm package tarball.
+	Tarball string `json:"tarball,omitempty"`
+}
+
+// LoadResponse contains the data returned by the kernel in response to a load
+// request.
+type LoadResponse struct {
+	result
+	Assembly string  `json:"assembly"`
+	Types    float64 `json:"types"`
+}
+
+// GetScriptCommandProps identifies a script from the "bin" section of a loaded
+// library's package.json, and the arguments to run it with.
+type GetScriptCommandProps struct {
+	Assembly string   `json:"assembly"`
+	Script   string   `json:"script"`
+	Args     []string `json:"args,omitempty"`
+}
+
+// GetScriptCommandResponse describes how to run a library's script: the
+// command, its arguments, and the complete environment to use.
+type GetScriptCommandResponse struct {
+	result
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+}
+
+// InvokeScriptProps identifies a script from the "bin" section of a loaded
+// library's package.json, and the arguments to run it with.
+type InvokeScriptProps struct {
+	Assembly string   `json:"assembly"`
+	Script   string   `json:"script"`
+	Args     []string `json:"args,omitempty"`
+}
+
+// InvokeScriptResponse describes the outcome of a script run by the kernel.
+// Status is nil if the script was terminated by a Signal.
+type InvokeScriptResponse struct {
+	result
+	Status *int    `json:"status"`
+	Stdout string  `json:"stdout"`
+	Stderr string  `json:"stderr"`
+	Signal *string `json:"signal"`
+}
+
+type NamingProps struct {
+	Assembly string `json:"assembly"`
+}
+
+type NamingResponse struct {
+	result
+	// readonly naming: {
+	//   readonly [language: string]: { readonly [key: string]: any } | undefined;
+	// };
+}
+
+type StatsResponse struct {
+	result
+	ObjectCount float64 `json:"objectCount"`
+	// Memory is the memory used by the kernel process, if it reports it.
+	Memory *MemoryUsage `json:"memory,omitempty"`
+}
+
+// MemoryUsage is the memory used by the kernel process, as reported by node's
+// process.memoryUsage(), in bytes.
+type MemoryUsage struct {
+	RSS       float64 `json:"rss"`
+	HeapTotal float64 `json:"heapTotal"`
+	HeapUsed  float64 `json:"heapUsed"`
+	External  float64 `json:"external"`
+}
+
+type CreateProps struct {
+	FQN        FQN           `json:"fqn"`
+	Interfaces []FQN         `json:"interfaces,omitempty"`
+	Arguments  []interface{} `json:"args,omitempty"`
+	Overrides  Overrides     `json:"overrides,omitempty"`
+}
+
+// TODO extends AnnotatedObjRef?
+type CreateResponse struct {
+	result
+	InstanceID string `json:"$jsii.byref"`
+}
+
+type DelProps struct {
+	ObjRef ObjectRef `json:"objref"`
+}
+
+type DelResponse struct {
+	result
+}
+
+type InvokeProps struct {
+	Method    string        `json:"method"`
+	Arguments []interface{} `json:"args"`
+	ObjRef    ObjectRef     `json:"objref"`
+}
+
+type StaticInvokeProps struct {
+	FQN       FQN           `json:"fqn"`
+	Method    string        `json:"method"`
+	Arguments []interface{} `json:"args"`
+}
+
+type InvokeResponse struct {
+	result
+	Result interface{} `json:"result"`
+}
+
+type GetProps struct {
+	Property string    `json:"property"`
+	ObjRef   ObjectRef `json:"objref"`
+}
+
+type StaticGetProps struct {
+	FQN      FQN    `json:"fqn"`
+	Property string `json:"property"`
+}
+
+type GetResponse struct {
+	result
+	Value interface{} `json:"value"`
+}
+
+type SetProps struct {
+	Property string      `json:"property"`
+	Value    interface{} `json:"value"`
+	ObjRef   ObjectRef   `json:"objref"`
+}
+
+type StaticSetProps struct {
+	FQN      FQN         `json:"fqn"`
+	Property string      `json:"property"`
+	Value    interface{} `json:"value"`
+}
+
+type SetResponse struct {
+	result
+}
+
+type BeginProps struct {
+	Method    *string       `json:"method"`
+	Arguments []interface{} `json:"args"`
+	ObjRef    ObjectRef     `json:"objref"`
+}
+
+type BeginResponse struct {
+	result
+	PromiseID *string `json:"promiseid"`
+}
+
+type EndProps struct {
+	PromiseID *string `json:"promiseid"`
+}
+
+type EndResponse struct {
+	result
+	Result interface{} `json:"result"`
+}
+
+// CallbacksResponse lists the pending asynchronous callbacks.
+type CallbacksResponse struct {
+	result
+	Callbacks []Callback `json:"callbacks"`
+}
+
+// CompleteProps notify the kernel of the completion of an asynchronous
+// callback. Synchronous callbacks are completed using a CallbackCompletion.
+type CompleteProps struct {
+	CallbackID *string     `json:"cbid"`
+	Error      *string     `json:"err"`
+	Result     interface{} `json:"result"`
+}
+
+type CompleteResponse struct {
+	result
+	CallbackID *string `json:"cbid"`
+}
@@ -0,0 +1,102 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequestEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		request Request
+		encoded string
+	}{
+		{NewRequest("stats", nil), `{"api":"stats"}`},
+		{NewRequest("del", DelProps{ObjRef: ObjectRef{InstanceID: "test.Thing@10000"}}), `{"api":"del","objref":{"$jsii.byref":"test.Thing@10000"}}`},
+		{
+			NewRequest("create", CreateProps{FQN: "test.Thing", Overrides: Overrides{MethodOverride{JsiiMethod: "run", GoMethod: "Run"}, PropertyOverride{JsiiProperty: "size", GoGetter: "Size"}}}),
+			`{"api":"create","fqn":"test.Thing","overrides":[{"method":"run","cookie":"Run"},{"property":"size","cookie":"Size"}]}`,
+		},
+	} {
+		encoded, err := json.Marshal(tc.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tc.encoded {
+			t.Errorf("expected %v, got %s", tc.encoded, encoded)
+		}
+
+		var decoded Request
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		var expected interface{}
+		if tc.request.Props != nil {
+			ptr := reflect.New(reflect.TypeOf(tc.request.Props))
+			ptr.Elem().Set(reflect.ValueOf(tc.request.Props))
+			expected = ptr.Interface()
+		}
+		if decoded.API != tc.request.API || !reflect.DeepEqual(decoded.Props, expected) {
+			t.Errorf("expected %#v to round-trip, got %#v", tc.request, decoded)
+		}
+	}
+
+	if _, err := json.Marshal(NewRequest("invoke", []string{"not", "an", "object"})); err == nil {
+		t.Error("expected an error for props that are not a JSON object")
+	}
+}
+
+func TestRequestDecoding(t *testing.T) {
+	var request Request
+	if err := json.Unmarshal([]byte(`{"api":"invoke","method":"add","args":[9007199254740993],"objref":{"$jsii.byref":"test.Calculator@10000"}}`), &request); err != nil {
+		t.Fatal(err)
+	}
+	props, ok := request.Props.(*InvokeProps)
+	if !ok {
+		t.Fatalf("expected *InvokeProps, got %T", request.Props)
+	}
+	if props.Arguments[0] != json.Number("9007199254740993") {
+		t.Errorf("expected the argument to be decoded without rounding, got %#v", props.Arguments[0])
+	}
+	if fqn := props.ObjRef.TypeFQN(); fqn != "test.Calculator" {
+		t.Errorf("expected test.Calculator, got %v", fqn)
+	}
+
+	if err := json.Unmarshal([]byte(`{"api":"future","answer":42}`), &request); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(request.Props, map[string]interface{}{"answer": json.Number("42")}) {
+		t.Errorf("expected the props of an unknown API to be decoded as a map, got %#v", request.Props)
+	}
+
+	if err := json.Unmarshal([]byte(`{"complete":{"cbid":"jsii::callback::1"}}`), &request); err == nil {
+		t.Error("expected an error for a message that is not an API request")
+	}
+	if err := json.Unmarshal([]byte(`{"api":"create","fqn":"test.Thing","overrides":[{"cookie":"Run"}]}`), &request); err == nil {
+		t.Error("expected an error for an override that is neither a method nor a property")
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	for _, tc := range []struct {
+		request  Request
+		problems []string
+	}{
+		{NewRequest("invoke", InvokeProps{Method: "add", ObjRef: ObjectRef{InstanceID: "test.Calculator@10000"}}), nil},
+		{NewRequest("invoke", InvokeProps{}), []string{"method is required", "objref must reference an object"}},
+		{NewRequest("create", CreateProps{FQN: "test.Thing", Overrides: Overrides{MethodOverride{GoMethod: "Run"}}}), []string{"override #0 has no method"}},
+		{NewRequest("stats", DelProps{}), []string{"stats requests have no props"}},
+		{NewRequest("", nil), []string{"request has no api"}},
+		{NewRequest("future", map[string]interface{}{}), nil},
+	} {
+		err := tc.request.Validate()
+		if tc.problems == nil && err != nil {
+			t.Errorf("expected %v to be valid, got %v", tc.request.API, err)
+		}
+		for _, problem := range tc.problems {
+			if err == nil || !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected %q in the error for %#v, got %v", problem, tc.request, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package protocol
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Result is implemented by the payloads of successful responses (such as
+// InvokeResponse). Its private method ensures no external type implements it.
+type Result interface {
+	isResult()
+}
+
+// result is embedded in all types that implement Result.
+type result struct{}
+
+func (r result) isResult() {}
+
+// Response is the envelope of all messages sent by the kernel in response to
+// a request. Exactly one of Ok, Callback or Error is expected to be set.
+//
+// The Ok field should be initialized with a pointer to the expected Result
+// before decoding, so that the payload is decoded straight into it, in a
+// single pass over the message. Otherwise, it is decoded as a map.
+type Response struct {
+	// Ok receives the payload of a successful response.
+	Ok interface{} `json:"ok,omitempty"`
+
+	// Callback is set when the kernel needs a callback to be fulfilled before it
+	// can send the final response.
+	Callback *Callback `json:"callback,omitempty"`
+
+	// Error, Stack and Name describe a failed request.
+	Error *string `json:"error,omitempty"`
+	Stack *string `json:"stack,omitempty"`
+	Name  *string `json:"name,omitempty"`
+}
+
+// NewResponse creates a response envelope that decodes successful payloads
+// into the provided result.
+func NewResponse(result Result) Response {
+	return Response{Ok: result}
+}
+
+// Err returns the error carried by this response, if any.
+func (r *Response) Err() *Error {
+	if r.Error == nil {
+		return nil
+	}
+	err := &Error{Message: *r.Error}
+	if r.Name != nil {
+		err.Name = *r.Name
+	}
+	if r.Stack != nil {
+		err.Stack = *r.Stack
+	}
+	return err
+}
+
+// FaultErrorName is the name of errors caused by a defect of the kernel, or
+// of its client, as opposed to errors thrown by JavaScript code.
+const FaultErrorName = "@jsii/kernel.Fault"
+
+// Error is an error response from the kernel.
+type Error struct {
+	Message string
+	// Name is the name of the JavaScript error, such as @jsii/kernel.Fault
+	// or @jsii/kernel.RuntimeError.
+	Name string
+	// Stack is the JavaScript stack trace of the error.
+	Stack string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsFault returns true if the error is a fault of the kernel.
+func (e *Error) IsFault() bool {
+	return e.Name == FaultErrorName
+}
+
+// Callback is a request from the kernel for the client to execute go code
+// (such as a method override). Exactly one of Invoke, Get or Set is set.
+type Callback struct {
+	CallbackID string          `json:"cbid"`
+	Cookie     string          `json:"cookie"`
+	Invoke     *InvokeCallback `json:"invoke,omitempty"`
+	Get        *GetCallback    `json:"get,omitempty"`
+	Set        *SetCallback    `json:"set,omitempty"`
+}
+
+type InvokeCallback struct {
+	Method    string        `json:"method"`
+	Arguments []interface{} `json:"args"`
+	ObjRef    ObjectRef     `json:"objref"`
+}
+
+type GetCallback struct {
+	Property string    `json:"property"`
+	ObjRef   ObjectRef `json:"objref"`
+}
+
+type SetCallback struct {
+	Property string      `json:"property"`
+	Value    interface{} `json:"value"`
+	ObjRef   ObjectRef   `json:"objref"`
+}
+
+// CallbackResult describes the outcome of a callback. A successful callback
+// has no Error.
+type CallbackResult struct {
+	CallbackID string      `json:"cbid"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"err,omitempty"`
+}
+
+// CallbackCompletion notifies the kernel of the completion of the synchronous
+// callback it is waiting for. Asynchronous callbacks are completed using the
+// "complete" API instead.
+type CallbackCompletion struct {
+	CallbackResult `json:"complete"`
+}
+
+// Hello is the first message sent by the kernel, identifying its version, and
+// optionally advertising the features it supports.
+type Hello struct {
+	Hello    string    `json:"hello"`
+	Features []Feature `json:"features,omitempty"`
+}
+
+// RuntimePackage returns the name of the kernel's package, extracted from its
+// package identifier (e.g: @jsii/runtime for @jsii/runtime@1.2.3).
+func (h *Hello) RuntimePackage() string {
+	if at := strings.LastIndex(h.Hello, "@"); at > 0 {
+		return h.Hello[:at]
+	}
+	return h.Hello
+}
+
+var helloSeparator = regexp.MustCompile("@")
+
+// RuntimeVersion returns the version of the kernel, extracted from its
+// package identifier (e.g: @jsii/runtime@1.2.3).
+func (h *Hello) RuntimeVersion() (*semver.Version, error) {
+	parts := helloSeparator.Split(h.Hello, 3)
+	switch len(parts) {
+	case 2:
+		return semver.NewVersion(parts[1])
+	case 3:
+		return semver.NewVersion(parts[2])
+	default:
+		return nil, fmt.Errorf("invalid handshake payload: %v", h.Hello)
+	}
+}
+
+// Exit requests the kernel to terminate, with the provided exit code.
+type Exit struct {
+	Code int `json:"exit"`
+}
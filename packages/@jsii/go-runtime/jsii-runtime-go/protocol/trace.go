@@ -0,0 +1,152 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SentPrefix prefixes the messages sent by a Conn in its trace.
+	SentPrefix = "> "
+	// ReceivedPrefix prefixes the messages received by a Conn in its trace.
+	ReceivedPrefix = "< "
+)
+
+// tracer copies the messages exchanged by a Conn to a trace writer, if one is
+// set. Messages are newline-delimited, and are written one line at a time.
+type tracer struct {
+	mutex sync.Mutex
+	out   io.Writer
+	// partial holds the beginning of the last message received, until it is
+	// complete.
+	partial []byte
+}
+
+func (t *tracer) set(out io.Writer) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.out = out
+	t.partial = nil
+}
+
+func (t *tracer) sent(message []byte) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.out != nil {
+		t.out.Write(append([]byte(SentPrefix), message...))
+	}
+}
+
+func (t *tracer) received(data []byte) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.out == nil {
+		return
+	}
+	t.partial = append(t.partial, data...)
+	for {
+		end := bytes.IndexByte(t.partial, '\n')
+		if end < 0 {
+			return
+		}
+		if line := bytes.TrimSpace(t.partial[:end]); len(line) > 0 {
+			t.out.Write(append(append([]byte(ReceivedPrefix), line...), '\n'))
+		}
+		t.partial = t.partial[end+1:]
+	}
+}
+
+type tracingReader struct {
+	io.Reader
+	*tracer
+}
+
+func (r tracingReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		r.received(p[:n])
+	}
+	return
+}
+
+type tracingWriter struct {
+	io.Writer
+	*tracer
+}
+
+func (w tracingWriter) Write(p []byte) (n int, err error) {
+	w.sent(p)
+	return w.Writer.Write(p)
+}
+
+// TraceTimeFormat is the format of the timestamps written by TimestampedTrace.
+const TraceTimeFormat = time.RFC3339Nano
+
+// TimestampedTrace returns a writer for use with Conn.SetTrace, which prefixes
+// each traced message with the time it was sent or received at, so that the
+// resulting trace records the timing of the session.
+func TimestampedTrace(out io.Writer) io.Writer {
+	return timestampWriter{out}
+}
+
+type timestampWriter struct {
+	out io.Writer
+}
+
+func (w timestampWriter) Write(line []byte) (int, error) {
+	stamped := append([]byte(time.Now().UTC().Format(TraceTimeFormat)+" "), line...)
+	if _, err := w.out.Write(stamped); err != nil {
+		return 0, err
+	}
+	return len(line), nil
+}
+
+// TraceLine is a message read from a trace, such as written by a Conn (see
+// SetTrace), optionally with timestamps (see TimestampedTrace).
+type TraceLine struct {
+	// Time is when the message was traced, or the zero time if the trace has no
+	// timestamps.
+	Time time.Time
+	// Sent is true for messages sent by the traced Conn (that is, requests to
+	// the kernel, if the Conn belongs to a client), false for those received.
+	Sent bool
+	// Message is the message, as JSON.
+	Message json.RawMessage
+}
+
+// ParseTraceLine parses a line of a trace.
+func ParseTraceLine(line string) (parsed TraceLine, err error) {
+	rest := strings.TrimSpace(line)
+	if !strings.HasPrefix(rest, SentPrefix) && !strings.HasPrefix(rest, ReceivedPrefix) {
+		space := strings.IndexByte(rest, ' ')
+		if space < 0 {
+			return parsed, fmt.Errorf("invalid trace line: %q", line)
+		}
+		if parsed.Time, err = time.Parse(TraceTimeFormat, rest[:space]); err != nil {
+			return parsed, fmt.Errorf("invalid trace line timestamp: %w", err)
+		}
+		rest = rest[space+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(rest, SentPrefix):
+		parsed.Sent, rest = true, rest[len(SentPrefix):]
+	case strings.HasPrefix(rest, ReceivedPrefix):
+		rest = rest[len(ReceivedPrefix):]
+	default:
+		return parsed, fmt.Errorf("invalid trace line, expected %q or %q: %q", SentPrefix, ReceivedPrefix, line)
+	}
+	if !json.Valid([]byte(rest)) {
+		return parsed, fmt.Errorf("invalid trace line message: %q", rest)
+	}
+	parsed.Message = json.RawMessage(rest)
+	return parsed, nil
+}
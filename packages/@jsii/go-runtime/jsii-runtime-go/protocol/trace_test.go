@@ -0,0 +1,63 @@
+package protocol
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrace(t *testing.T) {
+	clientSide, kernelSide := net.Pipe()
+	defer clientSide.Close()
+	go func() {
+		defer kernelSide.Close()
+		conn := NewConn(kernelSide)
+		var request Request
+		conn.Receive(&request)
+		conn.Send(Response{Ok: StatsResponse{ObjectCount: 3}})
+	}()
+
+	var trace bytes.Buffer
+	conn := NewConn(clientSide)
+	conn.SetTrace(TimestampedTrace(&trace))
+	if err := conn.Send(NewRequest("stats", nil)); err != nil {
+		t.Fatal(err)
+	}
+	var res StatsResponse
+	env := NewResponse(&res)
+	if err := conn.Receive(&env); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetTrace(nil)
+
+	lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", trace.String())
+	}
+	for i, expected := range []struct {
+		sent    bool
+		message string
+	}{{true, `{"api":"stats"}`}, {false, `{"ok":{"objectCount":3}}`}} {
+		line, err := ParseTraceLine(lines[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line.Sent != expected.sent || string(line.Message) != expected.message {
+			t.Errorf("expected %v (sent: %v), got %s (sent: %v)", expected.message, expected.sent, line.Message, line.Sent)
+		}
+		if time.Since(line.Time) > time.Minute {
+			t.Errorf("unexpected timestamp: %v", line.Time)
+		}
+	}
+
+	if line, err := ParseTraceLine(`> {"api":"stats"}`); err != nil || !line.Time.IsZero() || !line.Sent {
+		t.Errorf("expected a line without timestamp to be parsed, got %#v (%v)", line, err)
+	}
+	for _, invalid := range []string{`{"api":"stats"}`, `yesterday > {}`, `> {`} {
+		if _, err := ParseTraceLine(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validator is implemented by props that can check they are well-formed.
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks that the request is for a known API, and that its props are
+// well-formed. Requests for unknown APIs are only checked to be named.
+func (r Request) Validate() error {
+	if r.API == "" {
+		return fmt.Errorf("request has no api")
+	}
+	if typ, known := propsTypes[r.API]; known && typ == nil && r.Props != nil {
+		return fmt.Errorf("%v requests have no props, but got %T", r.API, r.Props)
+	}
+	if validator, ok := r.Props.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("invalid %v request: %w", r.API, err)
+		}
+	}
+	return nil
+}
+
+// problems collects the problems found while validating a value.
+type problems []string
+
+func (p *problems) require(ok bool, format string, args ...interface{}) {
+	if !ok {
+		*p = append(*p, fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *problems) requireRef(ref ObjectRef, field string) {
+	p.require(ref.InstanceID != "", "%v must reference an object", field)
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v", strings.Join(p, "; "))
+}
+
+func (o ObjectRef) Validate() error {
+	var p problems
+	p.requireRef(o, "objref")
+	return p.err()
+}
+
+func (p LoadProps) Validate() error {
+	var errs problems
+	errs.require(p.Name != "", "name is required")
+	errs.require(p.Version != "", "version is required")
+	errs.require(p.Tarball != "", "tarball is required")
+	return errs.err()
+}
+
+func (p GetScriptCommandProps) Validate() error {
+	var errs problems
+	errs.require(p.Assembly != "", "assembly is required")
+	errs.require(p.Script != "", "script is required")
+	return errs.err()
+}
+
+func (p InvokeScriptProps) Validate() error {
+	var errs problems
+	errs.require(p.Assembly != "", "assembly is required")
+	errs.require(p.Script != "", "script is required")
+	return errs.err()
+}
+
+func (p NamingProps) Validate() error {
+	var errs problems
+	errs.require(p.Assembly != "", "assembly is required")
+	return errs.err()
+}
+
+func (p CreateProps) Validate() error {
+	var errs problems
+	errs.require(p.FQN != "", "fqn is required")
+	for i, o := range p.Overrides {
+		switch o := o.(type) {
+		case MethodOverride:
+			errs.require(o.JsiiMethod != "", "override #%d has no method", i)
+		case *MethodOverride:
+			errs.require(o != nil && o.JsiiMethod != "", "override #%d has no method", i)
+		case PropertyOverride:
+			errs.require(o.JsiiProperty != "", "override #%d has no property", i)
+		case *PropertyOverride:
+			errs.require(o != nil && o.JsiiProperty != "", "override #%d has no property", i)
+		default:
+			errs.require(false, "override #%d is a %T", i, o)
+		}
+	}
+	return errs.err()
+}
+
+func (p DelProps) Validate() error {
+	var errs problems
+	errs.requireRef(p.ObjRef, "objref")
+	return errs.err()
+}
+
+func (p InvokeProps) Validate() error {
+	var errs problems
+	errs.require(p.Method != "", "method is required")
+	errs.requireRef(p.ObjRef, "objref")
+	return errs.err()
+}
+
+func (p StaticInvokeProps) Validate() error {
+	var errs problems
+	errs.require(p.FQN != "", "fqn is required")
+	errs.require(p.Method != "", "method is required")
+	return errs.err()
+}
+
+func (p GetProps) Validate() error {
+	var errs problems
+	errs.require(p.Property != "", "property is required")
+	errs.requireRef(p.ObjRef, "objref")
+	return errs.err()
+}
+
+func (p StaticGetProps) Validate() error {
+	var errs problems
+	errs.require(p.FQN != "", "fqn is required")
+	errs.require(p.Property != "", "property is required")
+	return errs.err()
+}
+
+func (p SetProps) Validate() error {
+	var errs problems
+	errs.require(p.Property != "", "property is required")
+	errs.requireRef(p.ObjRef, "objref")
+	return errs.err()
+}
+
+func (p StaticSetProps) Validate() error {
+	var errs problems
+	errs.require(p.FQN != "", "fqn is required")
+	errs.require(p.Property != "", "property is required")
+	return errs.err()
+}
+
+func (p BeginProps) Validate() error {
+	var errs problems
+	errs.require(p.Method != nil && *p.Method != "", "method is required")
+	errs.requireRef(p.ObjRef, "objref")
+	return errs.err()
+}
+
+func (p EndProps) Validate() error {
+	var errs problems
+	errs.require(p.PromiseID != nil && *p.PromiseID != "", "promiseid is required")
+	return errs.err()
+}
+
+func (p CompleteProps) Validate() error {
+	var errs problems
+	errs.require(p.CallbackID != nil && *p.CallbackID != "", "cbid is required")
+	return errs.err()
+}
+
+// Validate checks that exactly one of Invoke, Get or Set is set.
+func (c Callback) Validate() error {
+	var errs problems
+	errs.require(c.CallbackID != "", "cbid is required")
+	count := 0
+	if c.Invoke != nil {
+		count++
+		errs.require(c.Invoke.Method != "", "invoke.method is required")
+		errs.requireRef(c.Invoke.ObjRef, "invoke.objref")
+	}
+	if c.Get != nil {
+		count++
+		errs.require(c.Get.Property != "", "get.property is required")
+		errs.requireRef(c.Get.ObjRef, "get.objref")
+	}
+	if c.Set != nil {
+		count++
+		errs.require(c.Set.Property != "", "set.property is required")
+		errs.requireRef(c.Set.ObjRef, "set.objref")
+	}
+	errs.require(count == 1, "exactly one of invoke, get or set is required, got %d", count)
+	return errs.err()
+}
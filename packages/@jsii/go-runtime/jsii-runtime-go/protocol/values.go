@@ -0,0 +1,66 @@
+package protocol
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// FQN represents a fully-qualified type name in the jsii type system.
+type FQN string
+
+// ObjectRef is a reference to an object managed by the kernel.
+type ObjectRef struct {
+	InstanceID string `json:"$jsii.byref"`
+	Interfaces []FQN  `json:"$jsii.interfaces,omitempty"`
+}
+
+var instanceIDPattern = regexp.MustCompile(`^(.+)@(\d+)$`)
+
+// TypeFQN returns the FQN of the object's dynamic type, as designated by its
+// instance ID. It panics if the instance ID is malformed.
+func (o *ObjectRef) TypeFQN() FQN {
+	if fqn, ok := o.TryTypeFQN(); ok {
+		return fqn
+	}
+	panic(fmt.Errorf("invalid instance id: %#v", o.InstanceID))
+}
+
+// TryTypeFQN is the same as TypeFQN, except it returns false instead of
+// panicking if the instance ID is malformed.
+func (o *ObjectRef) TryTypeFQN() (fqn FQN, ok bool) {
+	if parts := instanceIDPattern.FindStringSubmatch(o.InstanceID); parts != nil {
+		fqn, ok = FQN(parts[1]), true
+	}
+	return
+}
+
+// EnumRef is a reference to an enum member.
+type EnumRef struct {
+	MemberFQN string `json:"$jsii.enum"`
+}
+
+// WireDate is a date, as an ISO-8601 timestamp.
+type WireDate struct {
+	Timestamp string `json:"$jsii.date"`
+}
+
+// WireMap is a map with string keys, the values of which are wire values.
+type WireMap struct {
+	MapData map[string]interface{} `json:"$jsii.map"`
+}
+
+// JSON is a JSON object value. Unlike other maps, it is exchanged with the jsii
+// kernel verbatim: it is not wrapped as a WireMap, and the values it contains
+// are not converted (e.g: into object references).
+type JSON map[string]interface{}
+
+// WireStruct is a struct (data type) passed by value.
+type WireStruct struct {
+	StructDescriptor `json:"$jsii.struct"`
+}
+
+// StructDescriptor identifies the type of a WireStruct, and holds its fields.
+type StructDescriptor struct {
+	FQN    FQN                    `json:"fqn"`
+	Fields map[string]interface{} `json:"data"`
+}
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"reflect"
+
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+)
+
+// Future is the eventual result of the invocation of an async method on a jsii
+// class instance, as returned by InvokeAsync.
+type Future struct {
+	future *kernel.Future
+}
+
+// InvokeAsync begins the invocation of an async method on a jsii class
+// instance, and returns a Future for its result. While the result is awaited,
+// go overrides of async methods called by the JavaScript code are each run in
+// their own goroutine, so they may block (e.g: on I/O, or on channels) without
+// preventing other work with the kernel from proceeding. The kernel is
+// notified of their completion once they return.
+//
+// Bindings generated by jsii-pacmak do not use InvokeAsync yet: the methods
+// they generate for async members call Invoke, which the kernel rejects for
+// async methods. Until they do, async methods must be invoked by calling
+// InvokeAsync directly, with the jsii name of the method (e.g: "doubleAsync").
+func InvokeAsync(obj interface{}, method string, args []interface{}) *Future {
+	client := kernel.GetClient()
+
+	// Find reference to class instance in client
+	ref, found := client.FindObjectRef(reflect.ValueOf(obj))
+
+	if !found {
+		panic("No Object Found")
+	}
+
+	future, err := client.InvokeAsync(kernel.InvokeProps{
+		Method:    method,
+		Arguments: convertArguments(args),
+		ObjRef:    ref,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	return &Future{future}
+}
+
+// Done returns a channel that is closed once the result of the async method
+// is available, so that it can be awaited in a select statement.
+func (f *Future) Done() <-chan struct{} {
+	return f.future.Done()
+}
+
+// Await waits for the async method to complete, and decodes its result into
+// ret (which may be nil for void methods). This panics if the async method
+// failed.
+func (f *Future) Await(ret interface{}) {
+	res, err := f.future.Result()
+	if err != nil {
+		panic(err)
+	}
+
+	if ret != nil {
+		kernel.GetClient().CastAndSetToPtr(ret, res.Result)
+	}
+}
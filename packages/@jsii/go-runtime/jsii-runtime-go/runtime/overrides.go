@@ -0,0 +1,243 @@
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+	goruntime "runtime"
+	"sort"
+	"strings"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/typeregistry"
+)
+
+// classOverrides determines which members of the jsii class designated by fqn
+// are overridden by inst, and verifies each of them against the signature of
+// the corresponding proxy method. Returns an error describing all problems at
+// once if an override has the wrong signature, or if a method that shadows a
+// member of the class is declared with a value receiver (and hence does not
+// override it).
+func classOverrides(registry *typeregistry.TypeRegistry, fqn api.FQN, inst interface{}) ([]api.Override, error) {
+	instVal := reflect.ValueOf(inst)
+	instType := instVal.Elem().Type()
+	classType, _ := registry.FindType(fqn)
+
+	mOverrides := getMethodOverrides(inst, "jsiiProxy_")
+	sort.Strings(mOverrides)
+
+	var (
+		overrides []api.Override
+		problems  []string
+	)
+	added := make(map[string]bool)
+	for _, name := range mOverrides {
+		override, ok := findOverride(registry, fqn, name)
+		if !ok {
+			continue
+		}
+		if problem := checkSignature(classType, fqn, instVal, name); problem != "" {
+			problems = append(problems, problem)
+		}
+		if !added[override.GoName()] {
+			added[override.GoName()] = true
+			overrides = append(overrides, override)
+		}
+	}
+
+	for _, name := range valueReceiverMethods(inst, "jsiiProxy_") {
+		if _, ok := findOverride(registry, fqn, name); ok {
+			problems = append(problems, fmt.Sprintf("%v is declared with a value receiver, so it does not override the member of %v; overriding methods must be declared with a pointer receiver", name, fqn))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, overridesError(instType, fqn, problems)
+	}
+
+	// If overriding struct has no overriding methods, could happen if
+	// overriding methods are not defined with pointer receiver.
+	if len(mOverrides) == 0 && !strings.HasPrefix(instType.Name(), "jsiiProxy_") {
+		return nil, fmt.Errorf("%v has no overriding methods. Overriding methods must be defined with a pointer receiver", instType.Name())
+	}
+
+	return overrides, nil
+}
+
+// explicitOverrides resolves the provided members of the jsii class designated
+// by fqn, and verifies that inst has a method with the correct signature for
+// each of them (or at least one of the getter and setter, for properties).
+// Methods are resolved from the method set of inst, which includes those
+// declared with a value receiver. Returns an error describing all problems at
+// once if this is not the case.
+func explicitOverrides(registry *typeregistry.TypeRegistry, fqn api.FQN, inst interface{}, members []Member) ([]api.Override, error) {
+	instVal := reflect.ValueOf(inst)
+	instType := instVal.Elem().Type()
+	classType, _ := registry.FindType(fqn)
+
+	var (
+		overrides []api.Override
+		problems  []string
+	)
+	added := make(map[string]bool)
+	for _, member := range members {
+		requested := member.toOverride()
+		name := requested.GoName()
+		override, ok := registry.GetOverride(fqn, name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%v is not a member of %v", name, fqn))
+			continue
+		}
+		if api.IsMethodOverride(requested) != api.IsMethodOverride(override) {
+			problems = append(problems, fmt.Sprintf("%v is declared as a %v, but it is a %v of %v", name, memberKind(requested), memberKind(override), fqn))
+			continue
+		}
+
+		methods := []string{name}
+		if api.IsPropertyOverride(override) {
+			methods = append(methods, fmt.Sprintf("Set%v", name))
+		}
+		implemented := false
+		for _, method := range methods {
+			if !instVal.MethodByName(method).IsValid() {
+				continue
+			}
+			implemented = true
+			if problem := checkSignature(classType, fqn, instVal, method); problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+		if !implemented {
+			problems = append(problems, fmt.Sprintf("%v is not declared by %v (nor any type it embeds)", strings.Join(methods, " or "), instType))
+			continue
+		}
+
+		if !added[name] {
+			added[name] = true
+			overrides = append(overrides, override)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, overridesError(instType, fqn, problems)
+	}
+	return overrides, nil
+}
+
+// checkSignature verifies that the method with the provided name on inst has
+// the same signature as the corresponding method of the jsii class type, and
+// returns a description of the problem if that is not the case.
+func checkSignature(classType reflect.Type, fqn api.FQN, inst reflect.Value, name string) string {
+	if classType == nil {
+		return ""
+	}
+	expected, found := classType.MethodByName(name)
+	if !found {
+		return ""
+	}
+	if actual := inst.MethodByName(name).Type(); actual != expected.Type {
+		return fmt.Sprintf("%v has signature %v, but it must be %v to override the member of %v", name, actual, expected.Type, fqn)
+	}
+	return ""
+}
+
+// overridesError creates an error listing the provided problems found with the
+// overrides of the jsii class designated by fqn by instType.
+func overridesError(instType reflect.Type, fqn api.FQN, problems []string) error {
+	return fmt.Errorf("%v has invalid overrides of %v:\n\t- %v", instType, fqn, strings.Join(problems, "\n\t- "))
+}
+
+// memberKind returns a human-readable description of the kind of member.
+func memberKind(override api.Override) string {
+	if api.IsMethodOverride(override) {
+		return "method"
+	}
+	return "property"
+}
+
+// findOverride returns the member of the jsii type designated by fqn that is
+// overridden by a go method with the provided name. Setter methods override
+// the corresponding property.
+func findOverride(registry *typeregistry.TypeRegistry, fqn api.FQN, name string) (api.Override, bool) {
+	if strings.HasPrefix(name, "Set") {
+		// Use getter's name even if setter is overriden
+		if override, ok := registry.GetOverride(fqn, name[3:]); ok {
+			return override, true
+		}
+	}
+	return registry.GetOverride(fqn, name)
+}
+
+// valueReceiverMethods returns the names of methods declared with a value
+// receiver by ptr's type and the types it embeds (excluding "base" structs
+// identified by the name prefix "basePrefix").
+func valueReceiverMethods(ptr interface{}, basePrefix string) (methods []string) {
+	ptrType := reflect.TypeOf(ptr)
+	if ptrType.Kind() != reflect.Ptr || ptrType.Elem().Kind() != reflect.Struct {
+		return
+	}
+	structType := ptrType.Elem()
+	if strings.HasPrefix(structType.Name(), basePrefix) {
+		return
+	}
+
+	structVal := reflect.ValueOf(ptr).Elem()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+		if field.Type.Kind() == reflect.Ptr || field.Type.Kind() == reflect.Interface {
+			if p := structVal.Field(i); !p.IsNil() {
+				methods = append(methods, valueReceiverMethods(p.Interface(), basePrefix)...)
+			}
+		}
+	}
+
+	for i := 0; i < structType.NumMethod(); i++ {
+		if name := structType.Method(i).Name; !promotedMethod(structType, name) {
+			methods = append(methods, name)
+		}
+	}
+	return
+}
+
+// promotedMethod returns true if the method with the provided name, which is
+// part of structType's method set, is promoted from one of its embedded fields
+// rather than declared by structType with a value receiver.
+func promotedMethod(structType reflect.Type, name string) bool {
+	provided := false
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+		// The method set of an embedded T only has T's value receiver methods,
+		// while those of *T and interfaces have all of their methods.
+		if _, ok := field.Type.MethodByName(name); ok {
+			provided = true
+			break
+		}
+	}
+	if !provided {
+		return false
+	}
+
+	// The method shadows one of an embedded field if it is declared. The
+	// compiler generates the method of *structType from that of structType,
+	// alongside the methods it promotes: these wrappers share a source position
+	// (that of no declaration), while declared methods have their own.
+	method, _ := structType.MethodByName(name)
+	wrapper, _ := reflect.PtrTo(structType).MethodByName(name)
+	return sourcePosition(method.Func) == sourcePosition(wrapper.Func)
+}
+
+// sourcePosition returns the position of the function's entry in the source,
+// as recorded by the compiler.
+func sourcePosition(fn reflect.Value) string {
+	pc := fn.Pointer()
+	if f := goruntime.FuncForPC(pc); f != nil {
+		file, line := f.FileLine(pc)
+		return fmt.Sprintf("%v:%d", file, line)
+	}
+	return ""
+}
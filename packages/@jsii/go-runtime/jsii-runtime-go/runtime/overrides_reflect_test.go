@@ -63,3 +63,29 @@ func TestOverrideReflection(t *testing.T) {
 		}
 	}
 }
+
+func TestOverrideReflectionIsStable(t *testing.T) {
+	// The second call is served from the cache, and must return the same result.
+	for i := 0; i < 2; i++ {
+		methods := getMethodOverrides(&D2{Name: "abc", IFace: &D1{&Base{}}}, "Base")
+		sort.Strings(methods)
+		if expected := []string{"M1", "M2", "X1", "X2"}; !reflect.DeepEqual(methods, expected) {
+			t.Errorf("expect: %v, got: %v", expected, methods)
+		}
+	}
+
+	// The embedded value's dynamic type is taken into account.
+	methods := getMethodOverrides(&D2{Name: "abc", IFace: &Base{}}, "Base")
+	sort.Strings(methods)
+	if expected := []string{"M2", "X2"}; !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expect: %v, got: %v", expected, methods)
+	}
+}
+
+func BenchmarkOverrideReflection(b *testing.B) {
+	val := &D2{Name: "abc", IFace: &D1{&Base{}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		getMethodOverrides(val, "Base")
+	}
+}
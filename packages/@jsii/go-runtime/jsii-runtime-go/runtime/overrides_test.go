@@ -0,0 +1,220 @@
+package runtime
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/typeregistry"
+)
+
+type Calculator interface {
+	Add(x *float64) *float64
+	Value() *float64
+	SetValue(v *float64)
+	Reset()
+}
+
+type jsiiProxy_Calculator struct{}
+
+func (c *jsiiProxy_Calculator) Add(x *float64) *float64 { return x }
+func (c *jsiiProxy_Calculator) Value() *float64         { return nil }
+func (c *jsiiProxy_Calculator) SetValue(v *float64)     {}
+func (c *jsiiProxy_Calculator) Reset()                  {}
+
+type goodCalculator struct{ Calculator }
+
+func (c *goodCalculator) Add(x *float64) *float64 { return x }
+func (c *goodCalculator) SetValue(v *float64)     {}
+func (c *goodCalculator) Helper()                 {}
+
+type badCalculator struct{ Calculator }
+
+func (c *badCalculator) Add(x *string) *float64 { return nil }
+func (c badCalculator) Value() *float64         { return nil }
+func (c *badCalculator) Reset()                 {}
+
+type valueCalculator struct{ Calculator }
+
+func (c valueCalculator) Add(x *float64) *float64 { return x }
+
+func registerCalculator(t *testing.T) *typeregistry.TypeRegistry {
+	registry := typeregistry.New()
+	members := []api.Override{
+		api.MethodOverride{JsiiMethod: "add", GoMethod: "Add"},
+		api.MethodOverride{JsiiMethod: "reset", GoMethod: "Reset"},
+		api.PropertyOverride{JsiiProperty: "value", GoGetter: "Value"},
+	}
+	if err := registry.RegisterClass("test.Calculator", reflect.TypeOf((*Calculator)(nil)).Elem(), members, func() interface{} { return &jsiiProxy_Calculator{} }); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestClassOverrides(t *testing.T) {
+	registry := registerCalculator(t)
+
+	overrides, err := classOverrides(registry, "test.Calculator", &goodCalculator{&jsiiProxy_Calculator{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []api.Override{
+		api.MethodOverride{JsiiMethod: "add", GoMethod: "Add"},
+		api.PropertyOverride{JsiiProperty: "value", GoGetter: "Value"},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("expected %v, got %v", expected, overrides)
+	}
+}
+
+func TestClassOverridesReportsAllProblems(t *testing.T) {
+	registry := registerCalculator(t)
+
+	_, err := classOverrides(registry, "test.Calculator", &badCalculator{&jsiiProxy_Calculator{}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{
+		"runtime.badCalculator has invalid overrides of test.Calculator",
+		"Add has signature func(*string) *float64, but it must be func(*float64) *float64",
+		"Value is declared with a value receiver",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in error: %v", expected, err)
+		}
+	}
+}
+
+func TestClassOverridesWithOnlyValueReceivers(t *testing.T) {
+	registry := registerCalculator(t)
+
+	_, err := classOverrides(registry, "test.Calculator", &valueCalculator{&jsiiProxy_Calculator{}})
+	if err == nil || !strings.Contains(err.Error(), "Add is declared with a value receiver") {
+		t.Errorf("expected a value receiver error, got %v", err)
+	}
+}
+
+type explicitCalculator struct{ Calculator }
+
+func (c explicitCalculator) Add(x *float64) *float64 { return x }
+func (c *explicitCalculator) SetValue(v *float64)    {}
+func (c *explicitCalculator) Helper()                {}
+
+func (c *explicitCalculator) JsiiOverrides() []Member {
+	return []Member{MemberMethod{GoMethod: "Add"}, MemberProperty{GoGetter: "Value"}}
+}
+
+func TestExplicitOverrides(t *testing.T) {
+	registry := registerCalculator(t)
+
+	inst := &explicitCalculator{&jsiiProxy_Calculator{}}
+	overrides, err := explicitOverrides(registry, "test.Calculator", inst, inst.JsiiOverrides())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Value receivers are accepted, and jsii names are resolved from the registry.
+	expected := []api.Override{
+		api.MethodOverride{JsiiMethod: "add", GoMethod: "Add"},
+		api.PropertyOverride{JsiiProperty: "value", GoGetter: "Value"},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("expected %v, got %v", expected, overrides)
+	}
+}
+
+// adder does not embed the class it overrides members of.
+type adder struct{}
+
+func (a adder) Add(x *float64) *float64 { return x }
+
+func TestExplicitOverridesReportsAllProblems(t *testing.T) {
+	registry := registerCalculator(t)
+
+	_, err := explicitOverrides(registry, "test.Calculator", &badCalculator{&jsiiProxy_Calculator{}}, []Member{
+		MemberMethod{GoMethod: "Add"},
+		MemberMethod{GoMethod: "Value"},
+		MemberMethod{GoMethod: "Missing"},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{
+		"Add has signature func(*string) *float64, but it must be func(*float64) *float64",
+		"Value is declared as a method, but it is a property of test.Calculator",
+		"Missing is not a member of test.Calculator",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in error: %v", expected, err)
+		}
+	}
+
+	// Members must be in the method set of the instance.
+	_, err = explicitOverrides(registry, "test.Calculator", &adder{}, []Member{MemberMethod{GoMethod: "Add"}, MemberProperty{GoGetter: "Value"}})
+	if err == nil || !strings.Contains(err.Error(), "Value or SetValue is not declared by runtime.adder") {
+		t.Errorf("expected an undeclared method error, got %v", err)
+	}
+}
+
+type shadowingCalculator struct{ Calculator }
+
+func (c shadowingCalculator) Value() *float64 { return nil }
+func (c shadowingCalculator) Helper()         {}
+
+func TestValueReceiverMethods(t *testing.T) {
+	if methods := valueReceiverMethods(&valueCalculator{&jsiiProxy_Calculator{}}, "jsiiProxy_"); !reflect.DeepEqual(methods, []string{"Add"}) {
+		t.Errorf("expected [Add], got %v", methods)
+	}
+
+	// Methods shadowing promoted methods are reported, but promoted methods
+	// are not.
+	if methods := valueReceiverMethods(&shadowingCalculator{&jsiiProxy_Calculator{}}, "jsiiProxy_"); !reflect.DeepEqual(methods, []string{"Helper", "Value"}) {
+		t.Errorf("expected [Helper Value], got %v", methods)
+	}
+	if methods := valueReceiverMethods(&goodCalculator{&jsiiProxy_Calculator{}}, "jsiiProxy_"); len(methods) != 0 {
+		t.Errorf("expected no methods, got %v", methods)
+	}
+}
+
+type Describable interface {
+	Describe() *string
+}
+
+type describingCalculator struct{ Calculator }
+
+func (c *describingCalculator) Add(x *float64) *float64 { return x }
+func (c *describingCalculator) Describe() *string       { return nil }
+
+func TestInstanceOverridesInExplicitMode(t *testing.T) {
+	registry := registerCalculator(t)
+	describe := api.MethodOverride{JsiiMethod: "describe", GoMethod: "Describe"}
+	if err := registry.RegisterInterface("test.IDescribable", reflect.TypeOf((*Describable)(nil)).Elem(), []api.Override{describe}, func() interface{} { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	add := api.MethodOverride{JsiiMethod: "add", GoMethod: "Add"}
+	inst := &describingCalculator{&jsiiProxy_Calculator{}}
+
+	_, overrides, err := instanceOverrides(registry, "test.Calculator", inst, classOverrides, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []api.Override{add, describe}; !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("expected %v, got %v", expected, overrides)
+	}
+
+	// Interface members that were not declared are not overridden, but the
+	// interfaces are still implemented.
+	explicit := func(registry *typeregistry.TypeRegistry, fqn api.FQN, inst interface{}) ([]api.Override, error) {
+		return explicitOverrides(registry, fqn, inst, []Member{MemberMethod{GoMethod: "Add"}})
+	}
+	interfaces, overrides, err := instanceOverrides(registry, "test.Calculator", inst, explicit, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []api.Override{add}; !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("expected %v, got %v", expected, overrides)
+	}
+	if expected := []api.FQN{"test.IDescribable"}; !reflect.DeepEqual(interfaces, expected) {
+		t.Errorf("expected %v, got %v", expected, interfaces)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/aws/jsii-runtime-go/internal/api"
 	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/aws/jsii-runtime-go/internal/typeregistry"
 )
 
 // FQN represents a fully-qualified type name in the jsii type system.
@@ -123,9 +125,65 @@ func IsAnonymousProxy(v interface{}) bool {
 	return kernel.GetClient().Types().IsAnonymousProxy(v)
 }
 
+// OverridesProvider can be implemented by go types that extend jsii classes in
+// order to explicitly declare which members of the class they override, rather
+// than having them discovered through reflection.
+type OverridesProvider interface {
+	// JsiiOverrides returns descriptors of the overridden members. Only the go
+	// names of the members are required (GoMethod for methods, and GoGetter
+	// for properties).
+	JsiiOverrides() []Member
+}
+
 // Create will construct a new JSII object within the kernel runtime. This is
-// called by jsii object constructors.
+// called by jsii object constructors. If inst implements OverridesProvider, the
+// overrides it declares are used, otherwise they are discovered by looking for
+// methods declared with a pointer receiver.
 func Create(fqn FQN, args []interface{}, inst interface{}) {
+	if provider, ok := inst.(OverridesProvider); ok {
+		CreateWithOverrides(fqn, args, inst, provider.JsiiOverrides())
+		return
+	}
+	create(fqn, args, inst, classOverrides, false)
+}
+
+// CreateWithOverrides is the same as Create, except the members of the class
+// overridden by inst are explicitly provided, rather than discovered through
+// reflection. Overriding methods may be declared with a value or a pointer
+// receiver, and are looked up in the method set of inst: members it only
+// inherits from the class it embeds must not be provided. Members of the jsii interfaces implemented by inst are not
+// overridden unless they are among the provided members. This panics if a
+// member does not belong to the class, or if inst does not declare a method
+// with the correct signature for it.
+func CreateWithOverrides(fqn FQN, args []interface{}, inst interface{}, members []Member) {
+	create(fqn, args, inst, func(registry *typeregistry.TypeRegistry, fqn api.FQN, inst interface{}) ([]api.Override, error) {
+		return explicitOverrides(registry, fqn, inst, members)
+	}, true)
+}
+
+// Implement creates a new object implementing the jsii interface designated by
+// fqn using the provided go functions, keyed by the jsii (e.g: "sayHello") or
+// go (e.g: "SayHello") name of the member they implement. Methods must be
+// implemented by functions with the same signature as the go interface's
+// method, and properties by a getter function or a value. The result is a
+// value of the go interface type, which can be passed to any API accepting it.
+// This panics if an implementation does not match the interface.
+//
+//	greeter := runtime.Implement("my-lib.IGreeter", map[string]interface{}{
+//		"greet": func(name *string) *string { return jsii.String("Hello, " + *name) },
+//	}).(mylib.IGreeter)
+func Implement(fqn FQN, members map[string]interface{}) interface{} {
+	result, err := kernel.GetClient().Implement(api.FQN(fqn), members)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// create implements Create and CreateWithOverrides, using the provided function
+// to determine the overrides of the class implemented by inst. If explicit is
+// true, these are the only overrides.
+func create(fqn FQN, args []interface{}, inst interface{}, findOverrides func(*typeregistry.TypeRegistry, api.FQN, interface{}) ([]api.Override, error), explicit bool) {
 	client := kernel.GetClient()
 
 	instVal := reflect.ValueOf(inst)
@@ -158,39 +216,11 @@ func Create(fqn FQN, args []interface{}, inst interface{}) {
 		}
 	}
 
-	// Find method overrides thru reflection
-	mOverrides := getMethodOverrides(inst, "jsiiProxy_")
-	// If overriding struct has no overriding methods, could happen if
-	// overriding methods are not defined with pointer receiver.
-	if len(mOverrides) == 0 && !strings.HasPrefix(instType.Name(), "jsiiProxy_") {
-		panic(fmt.Errorf("%v has no overriding methods. Overriding methods must be defined with a pointer receiver", instType.Name()))
-	}
-	var overrides []api.Override
-	registry := client.Types()
-	added := make(map[string]bool)
-	for _, name := range mOverrides {
-		// Use getter's name even if setter is overriden
-		if strings.HasPrefix(name, "Set") {
-			propName := name[3:]
-			if override, ok := registry.GetOverride(api.FQN(fqn), propName); ok {
-				if !added[propName] {
-					added[propName] = true
-					overrides = append(overrides, override)
-				}
-				continue
-			}
-		}
-		if override, ok := registry.GetOverride(api.FQN(fqn), name); ok {
-			if !added[name] {
-				added[name] = true
-				overrides = append(overrides, override)
-			}
-		}
+	interfaces, overrides, err := instanceOverrides(client.Types(), api.FQN(fqn), inst, findOverrides, explicit)
+	if err != nil {
+		panic(err)
 	}
 
-	interfaces, newOverrides := client.Types().DiscoverImplementation(instType)
-	overrides = append(overrides, newOverrides...)
-
 	res, err := client.Create(kernel.CreateProps{
 		FQN:        api.FQN(fqn),
 		Arguments:  convertArguments(args),
@@ -207,6 +237,23 @@ func Create(fqn FQN, args []interface{}, inst interface{}) {
 	}
 }
 
+// instanceOverrides determines the jsii interfaces implemented by inst, as well
+// as the members of the class designated by fqn and of these interfaces that it
+// overrides. In explicit mode, the members returned by findOverrides are the
+// only ones overridden, and interface members are not discovered.
+func instanceOverrides(registry *typeregistry.TypeRegistry, fqn api.FQN, inst interface{}, findOverrides func(*typeregistry.TypeRegistry, api.FQN, interface{}) ([]api.Override, error), explicit bool) (interfaces []api.FQN, overrides []api.Override, err error) {
+	// Find method overrides, and verify them
+	if overrides, err = findOverrides(registry, fqn, inst); err != nil {
+		return
+	}
+
+	interfaces, newOverrides := registry.DiscoverImplementation(reflect.TypeOf(inst).Elem())
+	if !explicit {
+		overrides = append(overrides, newOverrides...)
+	}
+	return
+}
+
 // Invoke will call a method on a jsii class instance. The response will be
 // decoded into the expected return type for the method being called.
 func Invoke(obj interface{}, method string, args []interface{}, ret interface{}) {
@@ -429,16 +476,39 @@ func getMethodOverridesRec(ptr interface{}, basePrefix string, cache map[string]
 		}
 	}
 	// Add overrides in current struct
+	for _, mn := range pointerOnlyMethods(ptrType) {
+		cache[mn] = true
+	}
+}
+
+// pointerOnlyMethodsCache associates a pointer type with the result of
+// pointerOnlyMethods for it. Method sets never change at runtime, so entries
+// never need to be invalidated.
+var pointerOnlyMethodsCache sync.Map // map[reflect.Type][]string
+
+// pointerOnlyMethods returns the names of methods that are in the method set
+// of the provided pointer type, but not in that of the type it points to (that
+// is, methods declared with a pointer receiver).
+func pointerOnlyMethods(ptrType reflect.Type) []string {
+	if cached, found := pointerOnlyMethodsCache.Load(ptrType); found {
+		return cached.([]string)
+	}
+
+	structType := ptrType.Elem()
 	// Current struct's value-type method-set
 	valMethods := make(map[string]bool)
 	for i := 0; i < structType.NumMethod(); i++ {
 		valMethods[structType.Method(i).Name] = true
 	}
 	// Compare current struct's pointer-type method-set to its value-type method-set
+	var methods []string
 	for i := 0; i < ptrType.NumMethod(); i++ {
 		mn := ptrType.Method(i).Name
 		if !valMethods[mn] {
-			cache[mn] = true
+			methods = append(methods, mn)
 		}
 	}
+
+	pointerOnlyMethodsCache.Store(ptrType, methods)
+	return methods
 }
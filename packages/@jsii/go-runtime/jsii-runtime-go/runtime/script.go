@@ -0,0 +1,25 @@
+package runtime
+
+import "github.com/aws/jsii-runtime-go/internal/kernel"
+
+// ScriptResult is the outcome of a script run using RunScript: its exit status
+// (-1 if it was terminated by a signal), and what it wrote to its standard
+// output and standard error.
+type ScriptResult = kernel.ScriptResult
+
+// RunScript runs a script from the "bin" section of the package.json of a jsii
+// library, which must have been loaded (see Load), with the provided arguments.
+// It returns the script's outcome once it has exited, and panics if it could
+// not be started. This allows go programs to expose the tools bundled with the
+// jsii libraries they use.
+func RunScript(pkg string, script string, args []string) ScriptResult {
+	result, err := kernel.GetClient().RunScript(kernel.GetScriptCommandProps{
+		Assembly: pkg,
+		Script:   script,
+		Args:     args,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package jsii
+
+import (
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/aws/jsii-runtime-go/protocol"
+)
+
+// RuntimeInformation describes the jsii kernel process used by this program: the
+// kernel package and version, the node executable running it, the embedded
+// bundle in use, and the protocol features negotiated with it.
+type RuntimeInformation = kernel.RuntimeInfo
+
+// MissingFeatureError is the error (panic) value raised when an operation
+// requires a protocol feature the jsii kernel does not support.
+type MissingFeatureError = protocol.MissingFeatureError
+
+// RuntimeInfo describes the jsii kernel process, starting it if that has not
+// happened yet. This panics if the kernel process cannot be started.
+func RuntimeInfo() RuntimeInformation {
+	info, err := kernel.GetClient().RuntimeInfo()
+	if err != nil {
+		panic(err)
+	}
+	return info
+}
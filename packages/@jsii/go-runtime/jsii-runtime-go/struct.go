@@ -0,0 +1,31 @@
+package jsii
+
+import "github.com/aws/jsii-runtime-go/internal/kernel"
+
+// StructEqual determines whether the two provided jsii struct values (or
+// pointers to such) are equal. Unlike reflect.DeepEqual, it only considers
+// the registered struct fields; follows pointers to primitive values; treats
+// nil values as unset (so they are only equal to other nil values); compares
+// nested structs and collections element-wise; and compares object references
+// by instance identity, without looking into proxy internals. Panics if either
+// value is not a registered jsii struct or pointer to one.
+func StructEqual(a, b interface{}) bool {
+	return kernel.GetClient().StructEqual(a, b)
+}
+
+// StructHash computes a hash code for the provided jsii struct value (or
+// pointer to one), which is consistent with StructEqual: equal values have the
+// same hash code. Panics if the value is not a registered jsii struct or
+// pointer to one.
+func StructHash(v interface{}) uint64 {
+	return kernel.GetClient().StructHash(v)
+}
+
+// StructClone returns a deep copy of the provided jsii struct value (or
+// pointer to one), with the same type as the provided value. Pointers,
+// collections and nested structs are copied, while object references are
+// shared with the original value. Panics if the value is not a registered
+// jsii struct or pointer to one.
+func StructClone(v interface{}) interface{} {
+	return kernel.GetClient().StructClone(v)
+}
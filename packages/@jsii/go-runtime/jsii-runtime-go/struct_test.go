@@ -0,0 +1,124 @@
+package jsii
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/jsii-runtime-go/internal/api"
+	"github.com/aws/jsii-runtime-go/internal/kernel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructEqualAndHash(t *testing.T) {
+	registerStructMocks(t)
+
+	when := time.Date(2021, time.March, 14, 15, 9, 26, 0, time.UTC)
+	makeProps := func() *MockProps {
+		return &MockProps{
+			Name:     String("root"),
+			Count:    Number(42),
+			Nested:   &MockNestedProps{Label: String("nested"), Kind: mockEnum_BETA, When: Time(when)},
+			List:     &[]*MockNestedProps{{Label: String("first")}},
+			Lookup:   &map[string]*string{"a": String("A"), "b": String("B"), "c": String("C")},
+			Anything: []interface{}{"free", 1337.0},
+		}
+	}
+
+	a, b := makeProps(), makeProps()
+	assert.True(t, StructEqual(a, b))
+	assert.True(t, StructEqual(*a, b))
+	assert.Equal(t, StructHash(a), StructHash(b))
+
+	// The same instant in a different location is equal.
+	b.Nested.When = Time(when.In(time.FixedZone("Elsewhere", 3600)))
+	assert.True(t, StructEqual(a, b))
+	assert.Equal(t, StructHash(a), StructHash(b))
+
+	// Unset optional fields are not equal to set ones.
+	b = makeProps()
+	b.Count = nil
+	assert.False(t, StructEqual(a, b))
+	assert.NotEqual(t, StructHash(a), StructHash(b))
+
+	b = makeProps()
+	(*b.List)[0].Kind = mockEnum_ALPHA
+	assert.False(t, StructEqual(a, b))
+
+	b = makeProps()
+	(*b.Lookup)["c"] = String("Not C")
+	assert.False(t, StructEqual(a, b))
+}
+
+func TestStructEqualComparesObjectsByIdentity(t *testing.T) {
+	registerStructMocks(t)
+	client := kernel.GetClient()
+
+	first := NewMockInterfaceA()
+	alias := NewMockInterfaceA()
+	other := NewMockInterfaceA()
+	client.RegisterInstance(reflect.ValueOf(first), api.ObjectRef{InstanceID: "mock.ClassA@20001"})
+	client.RegisterInstance(reflect.ValueOf(alias), api.ObjectRef{InstanceID: "mock.ClassA@20001"})
+	client.RegisterInstance(reflect.ValueOf(other), api.ObjectRef{InstanceID: "mock.ClassA@20002"})
+
+	a := MockProps{Name: String("root"), Anything: first}
+	assert.True(t, StructEqual(a, MockProps{Name: String("root"), Anything: alias}))
+	assert.Equal(t, StructHash(a), StructHash(MockProps{Name: String("root"), Anything: alias}))
+	assert.False(t, StructEqual(a, MockProps{Name: String("root"), Anything: other}))
+}
+
+// mockPoint is not a jsii struct.
+type mockPoint struct {
+	X     int
+	label string
+}
+
+func TestStructEqualComparesOtherStructsDeeply(t *testing.T) {
+	registerStructMocks(t)
+
+	a := MockProps{Name: String("root"), Anything: mockPoint{X: 1, label: "one"}}
+	assert.True(t, StructEqual(a, MockProps{Name: String("root"), Anything: mockPoint{X: 1, label: "one"}}))
+	assert.Equal(t, StructHash(a), StructHash(MockProps{Name: String("root"), Anything: mockPoint{X: 1, label: "one"}}))
+
+	for _, other := range []mockPoint{{X: 2, label: "one"}, {X: 1, label: "two"}} {
+		b := MockProps{Name: String("root"), Anything: other}
+		assert.False(t, StructEqual(a, b))
+		assert.NotEqual(t, StructHash(a), StructHash(b))
+	}
+}
+
+func TestStructClone(t *testing.T) {
+	registerStructMocks(t)
+
+	object := NewMockInterfaceA()
+	original := &MockProps{
+		Name:     String("root"),
+		Nested:   &MockNestedProps{Label: String("nested")},
+		List:     &[]*MockNestedProps{{Label: String("first")}},
+		Lookup:   &map[string]*string{"key": String("value")},
+		Anything: object,
+	}
+
+	clone := StructClone(original).(*MockProps)
+	assert.True(t, StructEqual(original, clone))
+	assert.Equal(t, original, clone)
+
+	// Nothing is shared, except for object references.
+	*clone.Name = "changed"
+	*clone.Nested.Label = "changed"
+	(*clone.List)[0].Label = String("changed")
+	(*clone.Lookup)["key"] = String("changed")
+	assert.Equal(t, "root", *original.Name)
+	assert.Equal(t, "nested", *original.Nested.Label)
+	assert.Equal(t, "first", *(*original.List)[0].Label)
+	assert.Equal(t, "value", *(*original.Lookup)["key"])
+	assert.Same(t, object, clone.Anything)
+
+	// Struct values are cloned as struct values.
+	assert.IsType(t, MockProps{}, StructClone(*original))
+}
+
+func TestStructHelpersRejectNonStructs(t *testing.T) {
+	assert.Panics(t, func() { StructHash("not a struct") })
+	assert.Panics(t, func() { StructEqual(nil, nil) })
+}
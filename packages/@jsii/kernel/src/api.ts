@@ -254,6 +254,18 @@ export interface StatsRequest {}
 
 export interface StatsResponse {
   readonly objectCount: number;
+  /**
+   * The memory used by the kernel process, as reported by
+   * `process.memoryUsage()`, in bytes.
+   */
+  readonly memory?: MemoryUsage;
+}
+
+export interface MemoryUsage {
+  readonly rss: number;
+  readonly heapTotal: number;
+  readonly heapUsed: number;
+  readonly external: number;
 }
 
 export type KernelRequest =
@@ -109,6 +109,7 @@ defineTest.skipIf = (expr: boolean) => (expr ? defineTest.skip : defineTest);
 defineTest('stats() return sandbox statistics', (sandbox) => {
   const stats = sandbox.stats({});
   expect(stats.objectCount).toBe(0);
+  expect(stats.memory?.heapUsed).toBeGreaterThan(0);
 
   for (let i = 0; i < 100; ++i) {
     sandbox.create({ fqn: '@scope/jsii-calc-lib.Number', args: [i] });
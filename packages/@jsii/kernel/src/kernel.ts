@@ -548,8 +548,10 @@ export class Kernel {
   }
 
   public stats(_req?: api.StatsRequest): api.StatsResponse {
+    const { rss, heapTotal, heapUsed, external } = process.memoryUsage();
     return {
       objectCount: this.objects.count,
+      memory: { rss, heapTotal, heapUsed, external },
     };
   }
 
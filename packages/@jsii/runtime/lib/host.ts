@@ -10,6 +10,22 @@ import { EventEmitter } from 'events';
 
 import { Input, IInputOutput } from './in-out';
 
+/**
+ * The optional protocol features supported by this host, which are advertised
+ * in the "hello" message so that clients do not need to infer them from the
+ * runtime's version. Each names a group of kernel APIs:
+ * - async: begin, end, callbacks and complete
+ * - naming: naming
+ * - scripts: getBinScriptCommand and invokeBinScript
+ * - stats: stats
+ */
+export const FEATURES: readonly string[] = [
+  'async',
+  'naming',
+  'scripts',
+  'stats',
+];
+
 export class KernelHost {
   private readonly kernel = new Kernel(this.callbackHandler.bind(this));
   private readonly eventEmitter = new EventEmitter();
@@ -3,7 +3,7 @@ import { api } from '@jsii/kernel';
 import { SyncStdio } from './sync-stdio';
 
 export type Output =
-  | { hello: string }
+  | { hello: string; features?: readonly string[] }
   | { ok: api.KernelResponse }
   | { callback: api.Callback }
   | { pending: true }
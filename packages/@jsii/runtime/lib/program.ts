@@ -1,5 +1,5 @@
 import * as packageInfo from '../package.json';
-import { KernelHost } from './host';
+import { FEATURES, KernelHost } from './host';
 import { InputOutput } from './in-out';
 import { SyncStdio } from './sync-stdio';
 
@@ -29,8 +29,8 @@ const host = new KernelHost(inout, { debug, noStack, debugTiming });
 
 host.once('exit', process.exit.bind(process));
 
-// say hello
-inout.write({ hello: `${name}@${version}` });
+// say hello, advertising the optional features we support
+inout.write({ hello: `${name}@${version}`, features: FEATURES });
 inout.debug = debug; // we don't want "hello" emitted
 
 host.run();
@@ -1,11 +1,11 @@
-import { api } from '@jsii/kernel';
+import { api, Kernel } from '@jsii/kernel';
 import * as spec from '@jsii/spec';
 import { loadAssemblyFromPath } from '@jsii/spec';
 import * as child from 'child_process';
 import * as fs from 'fs';
 import * as path from 'path';
 
-import { KernelHost, IInputOutput, Input, Output } from '../lib';
+import { FEATURES, KernelHost, IInputOutput, Input, Output } from '../lib';
 
 test('can load libraries from within a callback', () => {
   const inout = new TestInputOutput([
@@ -36,6 +36,22 @@ test('can load libraries from within a callback', () => {
   });
 });
 
+test('advertises features the kernel implements', () => {
+  const featureApis: Record<string, readonly string[]> = {
+    async: ['begin', 'end', 'callbacks', 'complete'],
+    naming: ['naming'],
+    scripts: ['getBinScriptCommand', 'invokeBinScript'],
+    stats: ['stats'],
+  };
+  const kernel = new Kernel(() => undefined);
+  for (const feature of FEATURES) {
+    expect(featureApis).toHaveProperty([feature]);
+    for (const name of featureApis[feature]) {
+      expect(typeof (kernel as any)[name]).toBe('function');
+    }
+  }
+});
+
 class TestInputOutput implements IInputOutput {
   private readonly inputCommands: Input[];
 